@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"sigs.k8s.io/yaml"
+)
+
+// classMappings maps nginx ingress classes onto the Traefik instance serving
+// their converted Ingresses, nil when no mapping file was given.
+var classMappings configs.ClassMappings
+
+// defaultIngressClass is the default IngressClass of the input, used to map
+// the Ingresses that set no class.
+var defaultIngressClass string
+
+// loadClassMappings reads the file given with --class-mapping-file, keyed by
+// nginx ingress class:
+//
+//	nginx-internal:
+//	  ingress_class: traefik-internal
+//	  http_entry_point: internal-web
+//	  https_entry_point: internal-websecure
+func loadClassMappings() error {
+	if cliCfg.ClassMappingFile == "" {
+		return nil
+	}
+
+	raw, err := os.ReadFile(cliCfg.ClassMappingFile)
+	if err != nil {
+		return fmt.Errorf("reading class mapping file: %w", err)
+	}
+
+	mappings := make(configs.ClassMappings)
+	if err = yaml.UnmarshalStrict(raw, &mappings); err != nil {
+		return fmt.Errorf("decoding class mapping file %q: %w", cliCfg.ClassMappingFile, err)
+	}
+
+	for class, mapping := range mappings {
+		if mapping == (configs.ClassMapping{}) {
+			return fmt.Errorf("class mapping file %q: ingress class %q maps to nothing", cliCfg.ClassMappingFile, class)
+		}
+	}
+
+	classMappings = mappings
+
+	logger.Debug("loaded class mappings", slog.String("file", cliCfg.ClassMappingFile), slog.Int("classes", len(mappings)))
+
+	return nil
+}
+
+// annotateIngressClasses sets the Traefik ingress class of the class
+// mappings on the converted objects, once canaries added theirs.
+func annotateIngressClasses(converted []*configs.Context) {
+	for _, ctx := range converted {
+		ctx.AnnotateIngressClass()
+	}
+}
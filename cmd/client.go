@@ -10,13 +10,26 @@ func setCLIClient(cmd *cobra.Command, _ []string) error {
 
 	kubeConfig.SetLogger(logger)
 
-	if cmd.Name() != "supported-annotations" {
+	if needsKubeClient(cmd) {
 		if err := kubeConfig.SetKubeClient(); err != nil {
 			return err
 		}
 	}
 
+	cliCfg.namespaceSet = cmd.Flags().Changed("namespace") || cmd.Flags().Changed("all")
+
 	kubeConfig.SetKubeNameSpace()
 
 	return nil
 }
+
+// needsKubeClient reports whether the command has to talk to the cluster.
+// Offline conversion from manifest files and the helm post-renderer never do.
+func needsKubeClient(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "supported-annotations", "post-render":
+		return false
+	default:
+		return !cliCfg.offline()
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
@@ -43,29 +43,55 @@ func getVersionCommand() *cobra.Command {
 
 func getConvertCommand() *cobra.Command {
 	convertCommand := &cobra.Command{
-		Use:     "convert [flags]",
-		Short:   "Converts the ingress nginx to equivalent trafik configs",
-		Long:    "Command that reads the existing nginx ingress and creates an alternatives in traefik, it auto maps annotations",
-		Example: ``,
+		Use:   "convert [flags]",
+		Short: "Converts the ingress nginx to equivalent trafik configs",
+		Long: "Command that reads the existing nginx ingress and creates an alternatives in traefik, it auto maps annotations.\n" +
+			"Ingresses are read from the cluster unless manifest files are passed with --ingress-file or -f/--file.",
+		Example: `nginx-traefik-converter convert -n namespace-one
+nginx-traefik-converter convert -f ./rendered/ -f 'charts/*/templates/ingress.yaml'
+nginx-traefik-converter convert -a -o traefik.yaml
+nginx-traefik-converter convert -f rendered.yaml -o - | kubectl apply -f -
+nginx-traefik-converter convert -a --controller-configmap ingress-nginx/ingress-nginx-controller`,
 		PreRunE: setCLIClient,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			ingresses, err := kubeConfig.ListAllIngresses()
+			if err := loadControllerDefaults(); err != nil {
+				return err
+			}
+
+			if err := loadClassMappings(); err != nil {
+				return err
+			}
+
+			ingresses, skipped, err := loadIngresses()
 			if err != nil {
 				return err
 			}
 
+			// Keep reports off stdout when it carries the converted manifests.
+			if cliCfg.ToFile == render.StdoutTarget {
+				printerConfig.Output = os.Stderr
+			}
+
+			sort.SliceStable(ingresses, func(i, j int) bool {
+				if ingresses[i].Namespace != ingresses[j].Namespace {
+					return ingresses[i].Namespace < ingresses[j].Namespace
+				}
+
+				return ingresses[i].Name < ingresses[j].Name
+			})
+
+			sink := render.NewSink(cliCfg.ToFile, cliCfg.OutputDir)
+
 			var globalReport configs.GlobalReport
 
+			globalReport.Skipped = append(globalReport.Skipped, skipped...)
+
 			seenCertSecrets := make(map[string]struct{})
+			converted := make([]*configs.Context, 0, len(ingresses))
 
 			for _, ingress := range ingresses {
-				res := configs.NewResult()
-				ctx := configs.New(&ingress, res, opts, logger)
-				ctx.CertLookup = kubeConfig
-				ctx.SeenCertSecrets = seenCertSecrets
-				ctx.StartIngressReport(ingress.Namespace, ingress.Name)
-
-				if err = convert.Run(*ctx); err != nil {
+				ctx, err := convertIngress(&ingress, certificateLookup(), seenCertSecrets)
+				if err != nil {
 					logger.Error("converting ingress to traefik errored",
 						slog.String("ingress", ingress.Name),
 						slog.String("error", err.Error()))
@@ -73,24 +99,46 @@ func getConvertCommand() *cobra.Command {
 					continue
 				}
 
-				if err = render.WriteYAML(*res, filepath.Join("./out", ingress.Namespace, ingress.Name)); err != nil {
+				converted = append(converted, ctx)
+			}
+
+			// Route priorities and canary Ingresses depend on every other
+			// Ingress, so they are resolved once all of them are converted.
+			convert.Priorities(converted)
+			convert.Canaries(converted)
+			annotateIngressClasses(converted)
+
+			for _, ctx := range converted {
+				res := ctx.Result
+
+				if err = sink.Write(ctx.Namespace, ctx.Ingress.Name, *res); err != nil {
 					logger.Error("writing converted traefik ingress errored",
-						slog.String("ingress", ingress.Name),
+						slog.String("ingress", ctx.Ingress.Name),
 						slog.String("error", err.Error()))
 
 					return err
 				}
 
-				if err = printerConfig.PrintIngressSummary(ctx.Result.IngressReport); err != nil {
+				if err = printerConfig.PrintIngressSummary(res.IngressReport); err != nil {
 					return err
 				}
 
 				globalReport.Ingresses = append(
 					globalReport.Ingresses,
-					ctx.Result.IngressReport,
+					res.IngressReport,
 				)
 			}
 
+			if err = sink.Close(); err != nil {
+				logger.Error("writing converted traefik objects errored", slog.String("error", err.Error()))
+
+				return err
+			}
+
+			for _, warning := range render.Warnings(sink) {
+				logger.Warn(warning)
+			}
+
 			if err = printerConfig.PrintGlobalSummary(globalReport); err != nil {
 				return err
 			}
@@ -103,11 +151,35 @@ func getConvertCommand() *cobra.Command {
 
 	convertCommand.SilenceErrors = true
 	registerCommonFlags(convertCommand)
+	registerFilterFlags(convertCommand)
 	registerImportFlags(convertCommand)
 
 	return convertCommand
 }
 
+func getPostRenderCommand() *cobra.Command {
+	postRenderCommand := &cobra.Command{
+		Use:   "post-render [flags]",
+		Short: "Helm post-renderer replacing nginx Ingresses with equivalent traefik configs",
+		Long: "Command that reads a manifest stream on stdin, replaces every nginx Ingress with the converted traefik objects " +
+			"and writes the combined stream to stdout, every other object is passed through untouched.\n" +
+			"It is meant to be used as a helm post-renderer and never talks to the cluster.",
+		Example: `helm upgrade my-release ./chart --post-renderer nginx-traefik-converter --post-renderer-args post-render`,
+		Args:    cobra.NoArgs,
+		PreRunE: setCLIClient,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return postRender(cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	postRenderCommand.SilenceErrors = true
+	postRenderCommand.SilenceUsage = true
+	registerFilterFlags(postRenderCommand)
+	registerConversionFlags(postRenderCommand)
+
+	return postRenderCommand
+}
+
 func getSupportedAnnotationCommand() *cobra.Command {
 	supportedAnnotationsCommand := &cobra.Command{
 		Use:     "supported-annotations [flags]",
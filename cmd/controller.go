@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/manifest"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// controllerConfig holds the ingress-nginx controller ConfigMap defaults
+// applied to every converted Ingress, nil when none was requested.
+var controllerConfig *configs.ControllerConfig
+
+// loadControllerConfig reads the controller ConfigMap selected with
+// --controller-configmap-file or --controller-configmap. The named ConfigMap
+// is looked up in docs when the input is not the cluster, so rendered
+// manifests that ship the controller ConfigMap can be converted offline.
+func loadControllerConfig(docs []manifest.Document, fromCluster bool) error {
+	var (
+		configMap *corev1.ConfigMap
+		source    string
+		err       error
+	)
+
+	switch {
+	case cliCfg.ControllerConfigMapFile != "":
+		source = cliCfg.ControllerConfigMapFile
+
+		fileDocs, err := manifest.ReadPaths([]string{source})
+		if err != nil {
+			return err
+		}
+
+		if configMap, err = manifest.ConfigMap(fileDocs, "", ""); err != nil {
+			return err
+		}
+
+		if configMap == nil {
+			return fmt.Errorf("no ConfigMap found in %q", source)
+		}
+	case cliCfg.ControllerConfigMap != "":
+		namespace, name := kubeConfig.DefaultNameSpace(), cliCfg.ControllerConfigMap
+		if ns, n, ok := strings.Cut(name, "/"); ok {
+			namespace, name = ns, n
+		}
+
+		source = namespace + "/" + name
+
+		if fromCluster {
+			if configMap, err = kubeConfig.GetConfigMap(namespace, name); err != nil {
+				return fmt.Errorf("fetching controller ConfigMap %s: %w", source, err)
+			}
+
+			break
+		}
+
+		if configMap, err = manifest.ConfigMap(docs, namespace, name); err != nil {
+			return err
+		}
+
+		if configMap == nil {
+			return fmt.Errorf("controller ConfigMap %s not found in the input manifests", source)
+		}
+	default:
+		return nil
+	}
+
+	controllerConfig = &configs.ControllerConfig{Source: source, Data: configMap.Data}
+
+	logger.Debug("loaded controller ConfigMap", slog.String("source", source), slog.Int("keys", len(configMap.Data)))
+
+	settings := controllerConfig.GlobalOnlySettings()
+	keys := make([]string, 0, len(settings))
+
+	for key := range settings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		logger.Warn(settings[key])
+	}
+
+	return nil
+}
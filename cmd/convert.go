@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/convert"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/filter"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// convertIngress runs the converters against a single Ingress and returns
+// its context, whose Result holds the translated Traefik objects together
+// with the Ingress report.
+func convertIngress(ingress *netv1.Ingress, certLookup configs.CertificateLookup, seenCertSecrets map[string]struct{}) (*configs.Context, error) {
+	res := configs.NewResult()
+	ctx := configs.New(ingress, res, opts, logger)
+	ctx.CertLookup = certLookup
+	ctx.SeenCertSecrets = seenCertSecrets
+	ctx.StartIngressReport(ingress.Namespace, ingress.Name)
+	ctx.NormalizeAnnotations(opts.AnnotationPrefixes)
+	ctx.ApplyControllerDefaults(controllerConfig)
+
+	class, _ := filter.IngressClass(ingress)
+	if class == "" {
+		class = defaultIngressClass
+	}
+
+	ctx.ApplyClassMapping(classMappings, class)
+
+	if err := convert.Run(*ctx); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/filter"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/kubernetes"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/render"
 	"github.com/spf13/cobra"
@@ -15,7 +17,24 @@ type Config struct {
 	LogLevel    string
 	IngressFile string
 	ToFile      string
+	OutputDir   string
 	Files       []string
+
+	IngressClasses   []string
+	IncludeClassless bool
+	LabelSelector    string
+	FieldSelector    string
+	Include          []string
+	Exclude          []string
+
+	ControllerConfigMap     string
+	ControllerConfigMapFile string
+
+	ClassMappingFile string
+
+	// namespaceSet records whether -n/--namespace or --all was given
+	// explicitly, in which case manifest files are filtered by namespace too.
+	namespaceSet bool
 }
 
 var (
@@ -31,24 +50,48 @@ func registerCommonFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(&cliCfg.LogLevel, "log-level", "", "INFO",
 		"log level for the nginx-traefik-converter")
 	cmd.PersistentFlags().StringVarP(&cliCfg.IngressFile, "ingress-file", "", "",
-		"path to ingress file")
+		"path to a manifest file, directory or glob to read Ingresses from instead of the cluster")
 	cmd.PersistentFlags().StringArrayVarP(&cliCfg.Files, "file", "f", nil,
-		"root yaml files to be used for importing")
+		"manifest files, directories or globs to read Ingresses from instead of the cluster (repeatable)")
 	cmd.PersistentFlags().BoolVarP(&cliCfg.NoColor, "no-color", "", false,
 		"when enabled the output would not be color encoded")
 	cmd.PersistentFlags().StringVarP(&kubeConfig.Context, "context", "c", "",
 		"kubernetes context to use")
-	cmd.PersistentFlags().StringVarP(&kubeConfig.NameSpace, "namespace", "n", "default",
-		"kubernetes namespace to set")
+	cmd.PersistentFlags().StringSliceVarP(&kubeConfig.NameSpaces, "namespace", "n", []string{"default"},
+		"kubernetes namespaces to consider, comma separated or repeatable")
 	cmd.PersistentFlags().BoolVarP(&kubeConfig.All, "all", "a", false,
 		"when set, all namespaces would be considered")
 }
 
+// Registers the flags that select which source Ingresses get converted.
+func registerFilterFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringArrayVarP(&cliCfg.IngressClasses, "ingress-class", "", filter.DefaultClasses,
+		"ingress class names or IngressClass controllers to convert (repeatable)")
+	cmd.PersistentFlags().BoolVarP(&cliCfg.IncludeClassless, "include-classless", "", false,
+		"when enabled, Ingresses without any ingress class are converted even when no default IngressClass exists")
+	cmd.PersistentFlags().StringVarP(&cliCfg.LabelSelector, "selector", "l", "",
+		"label selector to filter Ingresses on, supports '=', '==', '!=', 'in' and 'notin' (e.g. -l team=payments)")
+	cmd.PersistentFlags().StringVarP(&cliCfg.FieldSelector, "field-selector", "", "",
+		"field selector to filter Ingresses on, supports metadata.name and metadata.namespace (e.g. --field-selector metadata.name!=legacy)")
+	cmd.PersistentFlags().StringArrayVarP(&cliCfg.Include, "include", "", nil,
+		"regular expression matched against the Ingress name or namespace/name, only matching Ingresses are converted (repeatable)")
+	cmd.PersistentFlags().StringArrayVarP(&cliCfg.Exclude, "exclude", "", nil,
+		"regular expression matched against the Ingress name or namespace/name, matching Ingresses are not converted (repeatable)")
+}
+
 func registerImportFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringVarP(&cliCfg.ToFile, "to-file", "", "",
-		"name of the file to which the final imported yaml should be written to")
+	cmd.PersistentFlags().StringVarP(&cliCfg.ToFile, "to-file", "o", "",
+		"write every converted object to this single multi-document yaml file, '-' writes to stdout")
+	cmd.PersistentFlags().StringVarP(&cliCfg.OutputDir, "output-dir", "", "./out",
+		"root directory of the per-ingress output tree, used when --to-file is not set")
 	cmd.PersistentFlags().BoolVarP(&printerConfig.Table, "table", "", false,
 		"when enabled prints output in table format")
+
+	registerConversionFlags(cmd)
+}
+
+// Registers the flags that tune how annotations are converted.
+func registerConversionFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolVarP(&opts.HelmWarnings, "helm-warnings", "", false,
 		"when enabled warns if an Ingress appears to be managed by Helm")
 	cmd.PersistentFlags().BoolVarP(&opts.CopyCertificates, "copy-certificates", "", false,
@@ -57,4 +100,20 @@ func registerImportFlags(cmd *cobra.Command) {
 		"when enabled won't consider the plugins while creating middlewares")
 	cmd.PersistentFlags().BoolVarP(&opts.ProxyBufferHeuristic, "proxy-buffer-heuristic", "", false,
 		"when enabled, the nginx ingress annotation 'proxy-buffer-size' gets heuristically mapped to Traefik buffering")
+	cmd.PersistentFlags().BoolVarP(&opts.LoosePrefixPaths, "loose-prefix-paths", "", false,
+		"when enabled, Prefix paths become plain PathPrefix matchers, so /foo also matches /foobar, "+
+			"instead of matching whole path elements as Kubernetes does")
+	cmd.PersistentFlags().StringVarP(&opts.HTTPEntryPoint, "http-entrypoint", "", configs.DefaultHTTPEntryPoint,
+		"Traefik entry point serving plain HTTP, in place of nginx's port 80")
+	cmd.PersistentFlags().StringVarP(&opts.HTTPSEntryPoint, "https-entrypoint", "", configs.DefaultHTTPSEntryPoint,
+		"Traefik entry point serving HTTPS, in place of nginx's port 443")
+	cmd.PersistentFlags().StringSliceVarP(&opts.AnnotationPrefixes, "annotation-prefix", "", []string{models.Prefix},
+		"annotation prefixes read by ingress-nginx (its --annotations-prefix), comma separated or repeatable, in order of precedence")
+	cmd.PersistentFlags().StringVarP(&cliCfg.ControllerConfigMap, "controller-configmap", "", "",
+		"ingress-nginx controller ConfigMap (namespace/name) whose global defaults apply unless an annotation overrides them")
+	cmd.PersistentFlags().StringVarP(&cliCfg.ControllerConfigMapFile, "controller-configmap-file", "", "",
+		"manifest file holding the ingress-nginx controller ConfigMap, used instead of --controller-configmap")
+	cmd.MarkFlagsMutuallyExclusive("controller-configmap", "controller-configmap-file")
+	cmd.PersistentFlags().StringVarP(&cliCfg.ClassMappingFile, "class-mapping-file", "", "",
+		"yaml file mapping nginx ingress classes to the Traefik ingress class annotation and entry points of their objects")
 }
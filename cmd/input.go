@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/filter"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/manifest"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// manifestPaths returns every file, directory or glob passed through
+// --ingress-file and -f/--file.
+func (cfg *Config) manifestPaths() []string {
+	paths := make([]string, 0, len(cfg.Files)+1)
+
+	if cfg.IngressFile != "" {
+		paths = append(paths, cfg.IngressFile)
+	}
+
+	return append(paths, cfg.Files...)
+}
+
+// offline reports whether Ingresses are read from manifests instead of the cluster.
+func (cfg *Config) offline() bool {
+	return len(cfg.manifestPaths()) != 0
+}
+
+// loadIngresses returns the Ingresses to convert, either from the manifest
+// files passed on the command line or from the cluster, keeping only those
+// in scope of the selectors and served by the selected ingress classes.
+// Objects found in manifests that are not Ingresses and Ingresses of other
+// classes are returned as skipped resources.
+func loadIngresses() ([]netv1.Ingress, []configs.SkippedResource, error) {
+	// Cluster listing is already scoped to the selected namespaces.
+	var namespaces []string
+	if cliCfg.offline() && cliCfg.namespaceSet && !kubeConfig.All {
+		namespaces = kubeConfig.NameSpaces
+	}
+
+	selector, err := newSelector(namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ingresses, classes, skipped, err := readIngresses()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selected, filtered := filterIngresses(ingresses, classes, selector)
+
+	return selected, append(skipped, filtered...), nil
+}
+
+// loadControllerDefaults loads the controller ConfigMap from the cluster or,
+// when running offline, from the manifest files.
+func loadControllerDefaults() error {
+	if cliCfg.ControllerConfigMap == "" || cliCfg.ControllerConfigMapFile != "" || !cliCfg.offline() {
+		return loadControllerConfig(nil, !cliCfg.offline())
+	}
+
+	docs, err := manifest.ReadPaths(cliCfg.manifestPaths())
+	if err != nil {
+		return err
+	}
+
+	return loadControllerConfig(docs, false)
+}
+
+// newSelector builds the Selector from the filter flags, limited to the
+// given namespaces when not empty.
+func newSelector(namespaces []string) (*filter.Selector, error) {
+	return filter.NewSelector(namespaces, cliCfg.LabelSelector, cliCfg.FieldSelector, cliCfg.Include, cliCfg.Exclude)
+}
+
+// readIngresses returns every Ingress and IngressClass of the input.
+func readIngresses() ([]netv1.Ingress, []netv1.IngressClass, []configs.SkippedResource, error) {
+	if !cliCfg.offline() {
+		ingresses, err := kubeConfig.ListAllIngresses(cliCfg.LabelSelector, cliCfg.FieldSelector)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		classes, err := kubeConfig.ListIngressClasses()
+		if err != nil {
+			logger.Warn("listing IngressClasses failed, ingress classes are matched by name only",
+				slog.String("error", err.Error()))
+		}
+
+		return ingresses, classes, nil, nil
+	}
+
+	docs, err := manifest.ReadPaths(cliCfg.manifestPaths())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ingresses, skipped, err := manifest.Ingresses(docs, kubeConfig.DefaultNameSpace())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	classes, err := manifest.IngressClasses(docs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logger.Debug("read ingresses from manifests",
+		slog.Int("ingresses", len(ingresses)),
+		slog.Int("skipped", len(skipped)))
+
+	return ingresses, classes, skipped, nil
+}
+
+// filterIngresses drops the Ingresses out of the selector's scope and splits
+// the others into those served by the selected ingress classes and skipped
+// entries for the rest.
+func filterIngresses(
+	ingresses []netv1.Ingress,
+	classes []netv1.IngressClass,
+	selector *filter.Selector,
+) ([]netv1.Ingress, []configs.SkippedResource) {
+	classFilter := filter.NewClassFilter(cliCfg.IngressClasses, classes, cliCfg.IncludeClassless)
+	defaultIngressClass = filter.DefaultClass(classes)
+
+	selected := make([]netv1.Ingress, 0, len(ingresses))
+	skipped := make([]configs.SkippedResource, 0)
+
+	for _, ingress := range ingresses {
+		if !selector.Match(&ingress) {
+			logger.Debug("ingress out of selection scope", slog.String("ingress", ingress.Namespace+"/"+ingress.Name))
+
+			continue
+		}
+
+		if ok, reason := classFilter.Match(&ingress); !ok {
+			logger.Debug("skipping ingress", slog.String("ingress", ingress.Name), slog.String("reason", reason))
+
+			skipped = append(skipped, configs.SkippedResource{
+				Kind:      "Ingress",
+				Namespace: ingress.Namespace,
+				Name:      ingress.Name,
+				Reason:    reason,
+			})
+
+			continue
+		}
+
+		selected = append(selected, ingress)
+	}
+
+	return selected, skipped
+}
+
+// certificateLookup returns the cluster Certificate lookup, or nil when
+// running offline so that Certificates are generated from annotations only.
+func certificateLookup() configs.CertificateLookup {
+	if cliCfg.offline() {
+		return nil
+	}
+
+	return kubeConfig
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/convert"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/filter"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/manifest"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/render"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// postRender reads a manifest stream from in, replaces every Ingress of the
+// selected ingress classes with the objects produced by the converters and
+// writes the combined stream to out. Every other document is copied through
+// untouched.
+//
+// Ingresses that fail to convert are passed through as well, so a helm
+// release never silently loses routes.
+func postRender(in io.Reader, out io.Writer) error {
+	docs, err := manifest.Decode(in, "-")
+	if err != nil {
+		return err
+	}
+
+	if err = loadControllerConfig(docs, false); err != nil {
+		return err
+	}
+
+	if err = loadClassMappings(); err != nil {
+		return err
+	}
+
+	ingresses, _, err := manifest.Ingresses(docs, "")
+	if err != nil {
+		return err
+	}
+
+	classes, err := manifest.IngressClasses(docs)
+	if err != nil {
+		return err
+	}
+
+	classFilter := filter.NewClassFilter(cliCfg.IngressClasses, classes, cliCfg.IncludeClassless)
+	defaultIngressClass = filter.DefaultClass(classes)
+
+	// Rendered charts rarely carry namespaces, so only labels, fields and
+	// name patterns apply here.
+	selector, err := newSelector(nil)
+	if err != nil {
+		return err
+	}
+
+	// Convert every Ingress first, route priorities are resolved and canaries
+	// merged into their primary Ingress once all of them are known.
+	converted := make([]*configs.Context, len(ingresses))
+	seenCertSecrets := make(map[string]struct{})
+
+	for i := range ingresses {
+		converted[i] = replacement(selector, classFilter, &ingresses[i], seenCertSecrets)
+	}
+
+	convert.Priorities(nonNil(converted))
+	convert.Canaries(nonNil(converted))
+	annotateIngressClasses(nonNil(converted))
+
+	writer := bufio.NewWriter(out)
+	stream := render.NewStream(writer)
+	next := 0
+
+	for _, doc := range docs {
+		if manifest.IsIngress(doc.Object) {
+			ctx := converted[next]
+			next++
+
+			if ctx != nil {
+				if err = writeReplacement(stream, ctx); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if err = writeDocument(stream, doc); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// replacement converts a single Ingress. It returns nil when the Ingress is
+// out of the selection scope, belongs to another class or failed to convert,
+// in which case the original Ingress should be kept.
+func replacement(
+	selector *filter.Selector,
+	classFilter *filter.ClassFilter,
+	ingress *netv1.Ingress,
+	seenCertSecrets map[string]struct{},
+) *configs.Context {
+	if !selector.Match(ingress) {
+		logger.Debug("passing ingress through, out of selection scope", slog.String("ingress", ingress.Name))
+
+		return nil
+	}
+
+	if ok, reason := classFilter.Match(ingress); !ok {
+		logger.Debug("passing ingress through", slog.String("ingress", ingress.Name), slog.String("reason", reason))
+
+		return nil
+	}
+
+	ctx, err := convertIngress(ingress, nil, seenCertSecrets)
+	if err != nil {
+		logger.Error("converting ingress to traefik errored, keeping the original ingress",
+			slog.String("ingress", ingress.Name),
+			slog.String("error", err.Error()))
+
+		return nil
+	}
+
+	return ctx
+}
+
+// writeReplacement writes the objects converted from an Ingress in its place.
+func writeReplacement(stream *render.Stream, ctx *configs.Context) error {
+	for _, warning := range ctx.Result.Warnings {
+		logger.Warn(warning, slog.String("ingress", ctx.Ingress.Name))
+	}
+
+	for _, obj := range render.ResultObjects(*ctx.Result) {
+		if err := stream.WriteObject(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func nonNil(contexts []*configs.Context) []*configs.Context {
+	out := make([]*configs.Context, 0, len(contexts))
+
+	for _, ctx := range contexts {
+		if ctx != nil {
+			out = append(out, ctx)
+		}
+	}
+
+	return out
+}
+
+// writeDocument copies a document to the stream, preferring its original
+// bytes so that formatting and comments are preserved.
+func writeDocument(stream *render.Stream, doc manifest.Document) error {
+	if len(doc.Raw) != 0 {
+		return stream.WriteRaw(doc.Raw)
+	}
+
+	return stream.WriteObject(doc.Object)
+}
@@ -0,0 +1,98 @@
+package configs
+
+import "fmt"
+
+// TraefikIngressClassAnnotation selects the Traefik instances whose CRD
+// provider, configured with the same ingressClass, load an object.
+const TraefikIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// ClassMapping describes the Traefik instance serving the Ingresses of an
+// nginx ingress class. Empty fields keep the defaults.
+type ClassMapping struct {
+	// IngressClass is set as the kubernetes.io/ingress.class annotation of
+	// every generated Traefik object.
+	IngressClass    string `yaml:"ingress_class,omitempty"     json:"ingress_class,omitempty"`
+	HTTPEntryPoint  string `yaml:"http_entry_point,omitempty"  json:"http_entry_point,omitempty"`
+	HTTPSEntryPoint string `yaml:"https_entry_point,omitempty" json:"https_entry_point,omitempty"`
+}
+
+// ClassMappings maps nginx ingress class names onto their Traefik instance.
+type ClassMappings map[string]ClassMapping
+
+// reportClassMapping is the report entry of Ingresses whose class has no
+// mapping.
+const reportClassMapping = "ingress-class-mapping"
+
+// ApplyClassMapping selects the Traefik instance of the given nginx ingress
+// class, if mappings has one. When mappings were loaded but none matches,
+// the objects would be loaded by every Traefik instance without an ingress
+// class filter, so the Ingress is reported.
+func (ctx *Context) ApplyClassMapping(mappings ClassMappings, class string) {
+	if mappings == nil {
+		return
+	}
+
+	if mapping, ok := mappings[class]; ok {
+		ctx.ClassMapping = &mapping
+
+		return
+	}
+
+	msg := fmt.Sprintf("ingress class %q has no entry in the class mapping file", class)
+	if class == "" {
+		msg = "the Ingress sets no ingress class and no default IngressClass is defined, so it matches no entry of the class mapping file"
+	}
+
+	msg += "; the generated objects get no " + TraefikIngressClassAnnotation +
+		" annotation and are loaded by every Traefik instance without an ingress class filter"
+
+	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+	ctx.ReportWarning(reportClassMapping, msg)
+}
+
+// AnnotateIngressClass sets the Traefik ingress class of the class mapping
+// on every generated Traefik object, so only the mapped Traefik instances
+// load them. cert-manager Certificates are left alone.
+func (ctx *Context) AnnotateIngressClass() {
+	if ctx.ClassMapping == nil || ctx.ClassMapping.IngressClass == "" {
+		return
+	}
+
+	objects := make([]annotated, 0)
+
+	for _, obj := range ctx.Result.Middlewares {
+		objects = append(objects, obj)
+	}
+
+	for _, obj := range ctx.Result.IngressRoutes {
+		objects = append(objects, obj)
+	}
+
+	for _, obj := range ctx.Result.TLSOptions {
+		objects = append(objects, obj)
+	}
+
+	for _, obj := range ctx.Result.TraefikServices {
+		objects = append(objects, obj)
+	}
+
+	for _, obj := range ctx.Result.ServersTransports {
+		objects = append(objects, obj)
+	}
+
+	for _, obj := range objects {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		annotations[TraefikIngressClassAnnotation] = ctx.ClassMapping.IngressClass
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// annotated is the part of metav1.Object AnnotateIngressClass needs.
+type annotated interface {
+	GetAnnotations() map[string]string
+	SetAnnotations(annotations map[string]string)
+}
@@ -29,7 +29,15 @@ type Context struct {
 	Options         *Options            `yaml:"options,omitempty" json:"options,omitempty"`
 	CertLookup      CertificateLookup   `yaml:"-" json:"-"`
 	SeenCertSecrets map[string]struct{} `yaml:"-" json:"-"`
-	Log             *slog.Logger
+	// ControllerConfig holds the ingress-nginx controller ConfigMap defaults, if loaded.
+	ControllerConfig *ControllerConfig `yaml:"controller_config,omitempty" json:"controller_config,omitempty"`
+	// GlobalDefaults maps the annotations filled in from ControllerConfig to their ConfigMap key.
+	GlobalDefaults map[string]string `yaml:"-" json:"-"`
+	// ClassMapping is the Traefik instance of the Ingress class, if one is mapped.
+	ClassMapping *ClassMapping `yaml:"class_mapping,omitempty" json:"class_mapping,omitempty"`
+	// AnnotationAliases maps normalised annotation keys to the key written on the Ingress.
+	AnnotationAliases map[string]string `yaml:"-" json:"-"`
+	Log               *slog.Logger
 }
 
 // append to prevent conflitcs with existing/future IngressRoute names
@@ -0,0 +1,180 @@
+package configs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+)
+
+// ControllerConfig holds the global defaults read from the ingress-nginx
+// controller ConfigMap. They apply to every Ingress unless the Ingress
+// overrides them with the matching annotation.
+type ControllerConfig struct {
+	// Source identifies the ConfigMap, "namespace/name" or a file path.
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// Data is the ConfigMap data.
+	Data map[string]string `yaml:"data,omitempty" json:"data,omitempty"`
+}
+
+// controllerDefault maps a controller ConfigMap key onto the annotation that
+// overrides it per Ingress.
+type controllerDefault struct {
+	key        string
+	annotation models.Annotation
+	// tlsOnly marks settings nginx applies only to Ingresses with spec.tls.
+	tlsOnly bool
+}
+
+// controllerDefaults lists the ConfigMap keys that are honoured as defaults.
+var controllerDefaults = []controllerDefault{
+	{key: "ssl-redirect", annotation: models.SSLRedirect, tlsOnly: true},
+	{key: "force-ssl-redirect", annotation: models.ForceSSLRedirect},
+	{key: "hsts", annotation: models.HSTS, tlsOnly: true},
+	{key: "hsts-max-age", annotation: models.HSTSMaxAge, tlsOnly: true},
+	{key: "hsts-include-subdomains", annotation: models.HSTSIncludeSubdomains, tlsOnly: true},
+	{key: "hsts-preload", annotation: models.HSTSPreload, tlsOnly: true},
+	{key: "proxy-body-size", annotation: models.ProxyBodySize},
+	{key: "whitelist-source-range", annotation: models.WhitelistSourceRange},
+	{key: "proxy-connect-timeout", annotation: models.ProxyConnectTimeout},
+	{key: "proxy-read-timeout", annotation: models.ProxyReadTimeout},
+	{key: "proxy-send-timeout", annotation: models.ProxySendTimeout},
+	{key: "proxy-next-upstream", annotation: models.ProxyNextUpstream},
+	{key: "proxy-next-upstream-tries", annotation: models.ProxyNextUpstreamTries},
+	{key: "proxy-next-upstream-timeout", annotation: models.ProxyNextUpstreamTimeout},
+	{key: "proxy-buffering", annotation: models.ProxyBuffering},
+	{key: "proxy-buffer-size", annotation: models.ProxyBufferSize},
+	{key: "enable-underscores-in-headers", annotation: models.UnderscoresInHeaders},
+}
+
+// globalAuthDefaults lists the external auth keys applied to every Ingress
+// unless it sets enable-global-auth: "false" or its own auth-url.
+var globalAuthDefaults = []controllerDefault{
+	{key: "global-auth-url", annotation: models.AuthURL},
+	{key: "global-auth-method", annotation: models.AuthMethod},
+	{key: "global-auth-signin", annotation: models.AuthSignin},
+	{key: "global-auth-signin-redirect-param", annotation: models.AuthSigninRedirectParam},
+	{key: "global-auth-response-headers", annotation: models.AuthResponseHeaders},
+	{key: "global-auth-request-redirect", annotation: models.AuthRequestRedirect},
+	{key: "global-auth-snippet", annotation: models.AuthSnippet},
+	{key: "global-auth-cache-key", annotation: models.AuthCacheKey},
+	{key: "global-auth-cache-duration", annotation: models.AuthCacheDuration},
+}
+
+// builtinControllerDefaults are ingress-nginx defaults that apply when a
+// ConfigMap is loaded but does not set the key.
+var builtinControllerDefaults = map[string]string{
+	"ssl-redirect": "true",
+	"hsts":         "true",
+}
+
+// controllerOnlyKeys lists ConfigMap keys with no per-Ingress annotation that
+// configure Traefik globally, with the hint reported for them.
+var controllerOnlyKeys = map[string]string{
+	"use-forwarded-headers":      "configure entryPoints.<name>.forwardedHeaders.trustedIPs in Traefik static configuration",
+	"compute-full-forwarded-for": "configure entryPoints.<name>.forwardedHeaders.trustedIPs in Traefik static configuration",
+	"forwarded-for-header":       "Traefik always uses X-Forwarded-For; a custom header requires a plugin",
+}
+
+// Value returns the effective value of a ConfigMap key, falling back to the
+// ingress-nginx built-in default.
+func (c *ControllerConfig) Value(key string) (string, bool) {
+	if v, ok := c.Data[key]; ok {
+		return strings.TrimSpace(v), true
+	}
+
+	v, ok := builtinControllerDefaults[key]
+
+	return v, ok
+}
+
+// GlobalOnlySettings returns the ConfigMap keys that can only be configured
+// globally in Traefik, with a hint on how to do so.
+func (c *ControllerConfig) GlobalOnlySettings() map[string]string {
+	settings := make(map[string]string)
+
+	for key, hint := range controllerOnlyKeys {
+		if v, ok := c.Data[key]; ok && strings.TrimSpace(v) != "" {
+			settings[key] = fmt.Sprintf("%s=%q is a controller-wide setting: %s", key, v, hint)
+		}
+	}
+
+	return settings
+}
+
+// ApplyControllerDefaults merges the controller ConfigMap defaults into the
+// annotations seen by the converters. Annotations set on the Ingress always
+// win. The Ingress object itself is left untouched and every merged key is
+// remembered so reports can tell it came from the global configuration.
+func (ctx *Context) ApplyControllerDefaults(controller *ControllerConfig) {
+	if controller == nil {
+		return
+	}
+
+	ctx.ControllerConfig = controller
+	ctx.GlobalDefaults = make(map[string]string)
+
+	merged := make(map[string]string, len(ctx.Annotations))
+	for k, v := range ctx.Annotations {
+		merged[k] = v
+	}
+
+	hasTLS := ctx.Ingress != nil && len(ctx.Ingress.Spec.TLS) > 0
+
+	apply := func(def controllerDefault) {
+		if def.tlsOnly && !hasTLS {
+			return
+		}
+
+		if _, ok := merged[string(def.annotation)]; ok {
+			return
+		}
+
+		value, ok := controller.Value(def.key)
+		if !ok || value == "" {
+			return
+		}
+
+		merged[string(def.annotation)] = value
+		ctx.GlobalDefaults[string(def.annotation)] = def.key
+	}
+
+	for _, def := range controllerDefaults {
+		apply(def)
+	}
+
+	if ctx.globalAuthEnabled(merged) {
+		for _, def := range globalAuthDefaults {
+			apply(def)
+		}
+	}
+
+	ctx.Annotations = merged
+}
+
+// globalAuthEnabled reports whether the global external auth applies to the
+// Ingress and records the enable-global-auth annotation when present.
+func (ctx *Context) globalAuthEnabled(annotations map[string]string) bool {
+	ann := string(models.EnableGlobalAuth)
+
+	_, configured := ctx.ControllerConfig.Value("global-auth-url")
+
+	value, set := annotations[ann]
+	if set {
+		switch {
+		case !configured:
+			ctx.ReportIgnored(ann, "no global-auth-url is configured in the controller ConfigMap")
+		case strings.EqualFold(strings.TrimSpace(value), "false"):
+			ctx.ReportConverted(ann)
+
+			return false
+		default:
+			ctx.ReportConverted(ann)
+		}
+	}
+
+	_, hasOwnAuth := annotations[string(models.AuthURL)]
+
+	return configured && !hasOwnAuth
+}
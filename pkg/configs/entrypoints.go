@@ -0,0 +1,38 @@
+package configs
+
+const (
+	// DefaultHTTPEntryPoint is the Traefik entry point serving plain HTTP
+	// unless Options.HTTPEntryPoint names another.
+	DefaultHTTPEntryPoint = "web"
+	// DefaultHTTPSEntryPoint is the Traefik entry point serving HTTPS unless
+	// Options.HTTPSEntryPoint names another.
+	DefaultHTTPSEntryPoint = "websecure"
+)
+
+// HTTPEntryPoint returns the Traefik entry point taking nginx's port 80. The
+// class mapping of the Ingress wins over the options.
+func (ctx *Context) HTTPEntryPoint() string {
+	if ctx.ClassMapping != nil && ctx.ClassMapping.HTTPEntryPoint != "" {
+		return ctx.ClassMapping.HTTPEntryPoint
+	}
+
+	if ctx.Options != nil && ctx.Options.HTTPEntryPoint != "" {
+		return ctx.Options.HTTPEntryPoint
+	}
+
+	return DefaultHTTPEntryPoint
+}
+
+// HTTPSEntryPoint returns the Traefik entry point taking nginx's port 443. The
+// class mapping of the Ingress wins over the options.
+func (ctx *Context) HTTPSEntryPoint() string {
+	if ctx.ClassMapping != nil && ctx.ClassMapping.HTTPSEntryPoint != "" {
+		return ctx.ClassMapping.HTTPSEntryPoint
+	}
+
+	if ctx.Options != nil && ctx.Options.HTTPSEntryPoint != "" {
+		return ctx.Options.HTTPSEntryPoint
+	}
+
+	return DefaultHTTPSEntryPoint
+}
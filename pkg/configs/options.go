@@ -5,7 +5,18 @@ type Options struct {
 	ProxyBufferHeuristic bool `yaml:"proxy_buffer_heuristic,omitempty" json:"proxy_buffer_heuristic,omitempty"`
 	DisablePlugins       bool `yaml:"disable_plugins,omitempty"        json:"disable_plugins,omitempty"`
 	HelmWarnings         bool `yaml:"helm_warnings,omitempty"          json:"helm_warnings,omitempty"`
-	CopyCertificates     bool `yaml:"copy_certificates,omitempty       json:"copy_certificates,omitempty"`
+	CopyCertificates     bool `yaml:"copy_certificates,omitempty"      json:"copy_certificates,omitempty"`
+	// AnnotationPrefixes are the annotation prefixes read by the controller,
+	// in order of precedence, see Context.NormalizeAnnotations.
+	AnnotationPrefixes []string `yaml:"annotation_prefixes,omitempty" json:"annotation_prefixes,omitempty"`
+	// LoosePrefixPaths converts Prefix paths to plain PathPrefix matchers,
+	// which also match "/foobar" for "/foo", instead of matching whole path
+	// elements as Kubernetes and nginx do.
+	LoosePrefixPaths bool `yaml:"loose_prefix_paths,omitempty" json:"loose_prefix_paths,omitempty"`
+	// HTTPEntryPoint and HTTPSEntryPoint name the Traefik entry points taking
+	// the HTTP and HTTPS traffic of nginx, see Context.HTTPEntryPoint.
+	HTTPEntryPoint  string `yaml:"http_entry_point,omitempty"  json:"http_entry_point,omitempty"`
+	HTTPSEntryPoint string `yaml:"https_entry_point,omitempty" json:"https_entry_point,omitempty"`
 }
 
 // NewOptions returns new instance of Options when invoked.
@@ -0,0 +1,121 @@
+package configs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+)
+
+// NormalizePrefix returns the annotation prefix with exactly one trailing
+// slash, so "ingress.kubernetes.io" and "ingress.kubernetes.io/" are equal.
+func NormalizePrefix(prefix string) string {
+	return strings.TrimSuffix(strings.TrimSpace(prefix), "/") + "/"
+}
+
+// NormalizeAnnotations rewrites the annotations using one of the given
+// prefixes to the default models.Prefix so the converters only ever look up
+// the canonical keys. Prefixes are listed in order of precedence: when the
+// same annotation is set under several of them, the first one wins and the
+// others are reported as ignored. Annotations under the default prefix are
+// dropped when it is not in the list, as ingress-nginx would not read them.
+//
+// The original key of every rewritten annotation is remembered so reports
+// show what is actually written on the Ingress.
+func (ctx *Context) NormalizeAnnotations(prefixes []string) {
+	if len(prefixes) == 0 {
+		return
+	}
+
+	rank := make(map[string]int, len(prefixes))
+
+	for i, prefix := range prefixes {
+		prefix = NormalizePrefix(prefix)
+		if _, ok := rank[prefix]; !ok {
+			rank[prefix] = i
+		}
+	}
+
+	if _, ok := rank[models.Prefix]; ok && len(rank) == 1 {
+		return
+	}
+
+	// Walk keys in a stable order so the reported conflicts are deterministic.
+	keys := make([]string, 0, len(ctx.Annotations))
+	for key := range ctx.Annotations {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	normalized := make(map[string]string, len(ctx.Annotations))
+	winner := make(map[string]string)
+	aliases := make(map[string]string)
+
+	for _, key := range keys {
+		prefix, name, ok := splitAnnotation(key, rank)
+		if !ok {
+			if strings.HasPrefix(key, models.Prefix) {
+				ctx.ReportIgnored(key, fmt.Sprintf("annotation prefix %q is not one of the configured annotation prefixes", models.Prefix))
+
+				continue
+			}
+
+			normalized[key] = ctx.Annotations[key]
+
+			continue
+		}
+
+		canonical := models.Prefix + name
+
+		if current, seen := winner[canonical]; seen {
+			if rank[prefix] > rank[annotationPrefix(current)] {
+				ctx.ReportIgnored(key, fmt.Sprintf("%s is also set and takes precedence", current))
+
+				continue
+			}
+
+			ctx.ReportIgnored(current, fmt.Sprintf("%s is also set and takes precedence", key))
+		}
+
+		winner[canonical] = key
+		normalized[canonical] = ctx.Annotations[key]
+
+		if key != canonical {
+			aliases[canonical] = key
+		} else {
+			delete(aliases, canonical)
+		}
+	}
+
+	// Set last, so that the conflicts above are reported with their raw keys.
+	ctx.Annotations = normalized
+	ctx.AnnotationAliases = aliases
+}
+
+// OriginalAnnotation returns the key the annotation was written with on the
+// Ingress, which differs from name when a custom prefix was normalised.
+func (ctx *Context) OriginalAnnotation(name string) string {
+	if original, ok := ctx.AnnotationAliases[name]; ok {
+		return original
+	}
+
+	return name
+}
+
+// splitAnnotation returns the configured prefix of key with the remaining
+// annotation name.
+func splitAnnotation(key string, rank map[string]int) (string, string, bool) {
+	prefix := annotationPrefix(key)
+	if _, ok := rank[prefix]; !ok || prefix == "" {
+		return "", "", false
+	}
+
+	return prefix, strings.TrimPrefix(key, prefix), true
+}
+
+// annotationPrefix returns the part of key up to and including the last slash.
+func annotationPrefix(key string) string {
+	return key[:strings.LastIndex(key, "/")+1]
+}
@@ -1,5 +1,7 @@
 package configs
 
+import "fmt"
+
 // AnnotationStatus represents the migration outcome of a single
 // NGINX Ingress annotation during conversion to Traefik.
 //
@@ -44,6 +46,11 @@ type AnnotationReportEntry struct {
 	// Message contains an optional human-readable explanation, typically
 	// used for warnings and skipped annotations.
 	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Source is set when the value did not come from the Ingress itself but
+	// from the controller ConfigMap, for example:
+	// "controller ConfigMap ingress-nginx/ingress-nginx-controller, key \"hsts\"".
+	Source string `yaml:"source,omitempty"  json:"source,omitempty"`
 }
 
 // IngressReport contains the migration report for a single Kubernetes Ingress.
@@ -58,10 +65,32 @@ type IngressReport struct {
 	Entries []AnnotationReportEntry `yaml:"entries,omitempty"   json:"entries,omitempty"`
 }
 
+// SkippedResource describes an input object that was not converted at all,
+// for example a non-Ingress object found in a manifest file.
+type SkippedResource struct {
+	// Kind is the kind of the skipped object.
+	Kind string `yaml:"kind,omitempty"      json:"kind,omitempty"`
+
+	// Namespace is the namespace of the skipped object, if any.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Name is the name of the skipped object.
+	Name string `yaml:"name,omitempty"      json:"name,omitempty"`
+
+	// Source is the file the object was read from, empty for cluster objects.
+	Source string `yaml:"source,omitempty"    json:"source,omitempty"`
+
+	// Reason explains why the object was skipped.
+	Reason string `yaml:"reason,omitempty"    json:"reason,omitempty"`
+}
+
 // GlobalReport aggregates migration reports for all processed Ingresses.
 type GlobalReport struct {
 	// Ingresses is the list of per-Ingress migration reports.
 	Ingresses []IngressReport `yaml:"ingresses,omitempty" json:"ingresses,omitempty"`
+
+	// Skipped is the list of input objects that were not converted.
+	Skipped []SkippedResource `yaml:"skipped,omitempty"   json:"skipped,omitempty"`
 }
 
 // StartIngressReport initializes a new per-Ingress report in the current
@@ -81,13 +110,25 @@ func (ctx *Context) addReport(name string, status AnnotationStatus, msg string)
 	ctx.Result.IngressReport.Entries = append(
 		ctx.Result.IngressReport.Entries,
 		AnnotationReportEntry{
-			Name:    name,
+			Name:    ctx.OriginalAnnotation(name),
 			Status:  status,
 			Message: msg,
+			Source:  ctx.annotationSource(name),
 		},
 	)
 }
 
+// annotationSource describes where the value of a defaulted annotation came
+// from, or returns an empty string when it is set on the Ingress.
+func (ctx *Context) annotationSource(name string) string {
+	key, ok := ctx.GlobalDefaults[name]
+	if !ok || ctx.ControllerConfig == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("controller ConfigMap %s, key %q", ctx.ControllerConfig.Source, key)
+}
+
 // ReportConverted records that the given annotation was successfully converted
 // into Traefik configuration without requiring manual action.
 func (ctx *Context) ReportConverted(name string) {
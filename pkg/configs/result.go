@@ -12,6 +12,14 @@ type Result struct {
 	TLSOptions    []*traefik.TLSOption    `yaml:"tls_options,omitempty"     json:"tls_options,omitempty"`
 	TLSOptionRefs map[string]string       `yaml:"tls_option_refs,omitempty" json:"tls_option_refs,omitempty"`
 
+	// TraefikServices holds the weighted services splitting traffic between
+	// a primary Ingress and its canary.
+	TraefikServices []*traefik.TraefikService `yaml:"traefik_services,omitempty" json:"traefik_services,omitempty"`
+
+	// ServersTransports holds the transport to the backends of the Ingress,
+	// referenced by every service of its IngressRoute.
+	ServersTransports []*traefik.ServersTransport `yaml:"servers_transports,omitempty" json:"servers_transports,omitempty"`
+
 	// Certificates holds cert-manager Certificate resources extracted from
 	// the cluster or generated from Ingress annotations. Stored as
 	// Unstructured to avoid pulling in the cert-manager Go module.
@@ -0,0 +1,396 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/ingressroute"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/middleware"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultCanaryWeightTotal is the ingress-nginx default for canary-weight-total.
+	defaultCanaryWeightTotal = 100
+
+	// canaryAlways and canaryNever are the header and cookie values that
+	// force or prevent routing to the canary.
+	canaryAlways = "always"
+	canaryNever  = "never"
+
+	// Canary routes only need to win over the primary route they split; the
+	// header is evaluated before the cookie, as in nginx.
+	canaryCookiePriority = 1
+	canaryHeaderPriority = 2
+)
+
+// canarySpec holds the parsed canary annotations of an Ingress.
+type canarySpec struct {
+	weight        int
+	weightTotal   int
+	header        string
+	headerValue   string
+	headerPattern string
+	cookie        string
+}
+
+// IsCanary reports whether the Ingress is an ingress-nginx canary.
+func IsCanary(ctx configs.Context) bool {
+	return strings.TrimSpace(ctx.Annotations[string(models.Canary)]) == "true"
+}
+
+// runCanary handles a canary Ingress. It produces no objects of its own: its
+// backends are merged into the primary Ingress by Canaries. Every annotation
+// other than the canary and session affinity ones is inherited from the
+// primary Ingress by nginx and is reported as ignored.
+func runCanary(ctx configs.Context) {
+	ctx.Log.Debug("running converter Canary")
+
+	canaryAnnotations := make(map[string]struct{}, len(models.CanaryAnnotations)+len(models.AffinityAnnotations))
+	for _, anns := range [][]models.Annotation{models.CanaryAnnotations, models.AffinityAnnotations} {
+		for _, ann := range anns {
+			canaryAnnotations[string(ann)] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(ctx.Annotations))
+
+	for key := range ctx.Annotations {
+		if _, ok := canaryAnnotations[key]; !ok && strings.HasPrefix(key, models.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ctx.ReportIgnored(key, "canary Ingresses inherit this annotation from their primary Ingress")
+	}
+
+	ingressroute.ReportAffinity(ctx)
+}
+
+// Canaries merges every canary Ingress into the primary Ingress serving the
+// same host and path in the same namespace. It must run once all Ingresses
+// have been converted.
+//
+//   - canary-weight splits the primary route through a weighted TraefikService.
+//   - canary-by-header and canary-by-cookie add higher-priority routes to the
+//     primary IngressRoute, matching the header or the Cookie header.
+//
+// The canary Ingress itself produces no IngressRoute, so it never competes
+// with its primary.
+func Canaries(contexts []*configs.Context) {
+	primaries := make(map[string]*configs.Context)
+
+	for _, ctx := range contexts {
+		if IsCanary(*ctx) {
+			continue
+		}
+
+		forEachBackendPath(ctx.Ingress, func(host string, path netv1.HTTPIngressPath) {
+			key := canaryKey(ctx.Namespace, host, path.Path)
+			if _, ok := primaries[key]; !ok {
+				primaries[key] = ctx
+			}
+		})
+	}
+
+	for _, ctx := range contexts {
+		if IsCanary(*ctx) {
+			mergeCanary(ctx, primaries)
+		}
+	}
+}
+
+func mergeCanary(canary *configs.Context, primaries map[string]*configs.Context) {
+	spec, err := parseCanary(*canary)
+	if err != nil {
+		canary.Result.Warnings = append(canary.Result.Warnings, err.Error())
+		reportCanary(canary, canary.ReportSkipped, err.Error())
+
+		return
+	}
+
+	merged := 0
+
+	forEachBackendPath(canary.Ingress, func(host string, path netv1.HTTPIngressPath) {
+		primary, ok := primaries[canaryKey(canary.Namespace, host, path.Path)]
+		if !ok {
+			canary.Result.Warnings = append(canary.Result.Warnings, fmt.Sprintf(
+				"canary path %q of host %q has no primary Ingress in namespace %q and was dropped, as nginx would ignore it",
+				path.Path, host, canary.Namespace,
+			))
+
+			return
+		}
+
+		if err := mergeCanaryPath(primary, canary, spec, host, path); err != nil {
+			canary.Result.Warnings = append(canary.Result.Warnings, err.Error())
+
+			return
+		}
+
+		merged++
+	})
+
+	if merged == 0 {
+		reportCanary(canary, canary.ReportSkipped, "no path of the canary Ingress could be merged into a primary Ingress")
+
+		return
+	}
+
+	reportCanary(canary, func(name, _ string) { canary.ReportConverted(name) }, "")
+}
+
+// mergeCanaryPath splits the routes of primary serving host and path with the
+// canary backend.
+func mergeCanaryPath(primary, canary *configs.Context, spec canarySpec, host string, path netv1.HTTPIngressPath) error {
+	canaryService, err := ingressroute.BackendService(*primary, path.Backend.Service)
+	if err != nil {
+		return fmt.Errorf("canary path %q of host %q: %w", path.Path, host, err)
+	}
+
+	// Session affinity is the canary's own, not inherited from the primary.
+	canaryService.Sticky = ingressroute.Sticky(*canary, path)
+
+	match := ingressroute.RouteMatch(*primary, host, path)
+	found := false
+
+	for _, ingressRoute := range primary.Result.IngressRoutes {
+		// Requests reaching the HTTPS redirect are never served by a backend.
+		if isRedirectRoute(primary, ingressRoute) {
+			continue
+		}
+
+		routes := ingressRoute.Spec.Routes
+
+		for i := range routes {
+			route := routes[i]
+			if route.Match != match {
+				continue
+			}
+
+			found = true
+
+			if len(route.Services) != 1 || route.Services[0].Kind == "TraefikService" {
+				return fmt.Errorf("route %q of %s already has several backends, canary Ingress %s was not merged",
+					match, ingressRoute.Name, canary.Ingress.Name)
+			}
+
+			basePriority := route.Priority
+			if basePriority == 0 {
+				// Traefik's default priority is the length of the rule.
+				basePriority = len(route.Match)
+			}
+
+			if spec.weight > 0 {
+				weighted, err := weightedCanaryService(primary, canary, route.Services[0], canaryService, spec)
+				if err != nil {
+					return fmt.Errorf("canary path %q of host %q was not merged: %w", path.Path, host, err)
+				}
+
+				ingressRoute.Spec.Routes[i].Services = []traefik.Service{{
+					LoadBalancerSpec: traefik.LoadBalancerSpec{Name: weighted.Name, Kind: "TraefikService"},
+				}}
+			}
+
+			for _, canaryRoute := range canaryRoutes(route, canaryService, spec, basePriority) {
+				ingressRoute.Spec.Routes = append(ingressRoute.Spec.Routes, canaryRoute)
+			}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("canary path %q of host %q: no route of primary Ingress %s serves it", path.Path, host, primary.Ingress.Name)
+	}
+
+	return nil
+}
+
+// isRedirectRoute reports whether ingressRoute is the companion of a TLS
+// IngressRoute of primary that only redirects HTTP requests to HTTPS.
+func isRedirectRoute(primary *configs.Context, ingressRoute *traefik.IngressRoute) bool {
+	if ingressRoute.Spec.TLS != nil {
+		return false
+	}
+
+	for _, tlsRoute := range primary.Result.IngressRoutes {
+		if tlsRoute.Spec.TLS != nil && tlsRoute.Name+middleware.RedirectRouteSuffix == ingressRoute.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canaryRoutes returns the header and cookie routes of a canary, copied from
+// the primary route so they keep its middlewares.
+func canaryRoutes(primary traefik.Route, canaryService traefik.Service, spec canarySpec, basePriority int) []traefik.Route {
+	routes := make([]traefik.Route, 0)
+
+	add := func(matcher string, services []traefik.Service, priority int) {
+		route := *primary.DeepCopy()
+		route.Match = primary.Match + " && " + matcher
+		route.Services = services
+		route.Priority = basePriority + priority
+		routes = append(routes, route)
+	}
+
+	canary := []traefik.Service{canaryService}
+
+	if spec.header != "" {
+		switch {
+		case spec.headerValue != "":
+			add(fmt.Sprintf("Header(`%s`, `%s`)", spec.header, spec.headerValue), canary, canaryHeaderPriority)
+		case spec.headerPattern != "":
+			add(fmt.Sprintf("HeaderRegexp(`%s`, `%s`)", spec.header, spec.headerPattern), canary, canaryHeaderPriority)
+		default:
+			add(fmt.Sprintf("Header(`%s`, `%s`)", spec.header, canaryAlways), canary, canaryHeaderPriority)
+			add(fmt.Sprintf("Header(`%s`, `%s`)", spec.header, canaryNever), primary.DeepCopy().Services, canaryHeaderPriority)
+		}
+	}
+
+	if spec.cookie != "" {
+		add(cookieMatcher(spec.cookie, canaryAlways), canary, canaryCookiePriority)
+		add(cookieMatcher(spec.cookie, canaryNever), primary.DeepCopy().Services, canaryCookiePriority)
+	}
+
+	return routes
+}
+
+// cookieMatcher matches a cookie value, Traefik has no dedicated cookie matcher.
+func cookieMatcher(name, value string) string {
+	return fmt.Sprintf("HeaderRegexp(`Cookie`, `(^|;\\s*)%s=%s(;|$)`)", regexp.QuoteMeta(name), value)
+}
+
+// weightedCanaryService returns the TraefikService splitting traffic between
+// the primary and canary backends, creating it on first use. It is named
+// after the primary Ingress and backend and the canary Ingress, and shared by
+// the paths of the canary with the same split. A path splitting the same
+// primary backend differently cannot be merged.
+func weightedCanaryService(primary, canary *configs.Context, primaryService, canaryService traefik.Service,
+	spec canarySpec,
+) (*traefik.TraefikService, error) {
+	name := primary.IngressName + "-" + primaryService.Name + "-" + canary.Ingress.Name
+
+	primaryWeight := max(spec.weightTotal-spec.weight, 0)
+	canaryWeight := spec.weight
+
+	primaryService.Weight = &primaryWeight
+	canaryService.Weight = &canaryWeight
+
+	weighted := &traefik.TraefikService{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "TraefikService",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: primary.Namespace,
+		},
+		Spec: traefik.TraefikServiceSpec{
+			Weighted: &traefik.WeightedRoundRobin{
+				Services: []traefik.Service{primaryService, canaryService},
+			},
+		},
+	}
+
+	// Keep sessions of a sticky canary on the side of the split they started on.
+	if canaryService.Sticky != nil {
+		cookie := *canaryService.Sticky.Cookie.DeepCopy()
+		cookie.Name += "-canary"
+		weighted.Spec.Weighted.Sticky = &dynamic.Sticky{Cookie: &cookie}
+	}
+
+	for _, existing := range primary.Result.TraefikServices {
+		if existing.Name != name {
+			continue
+		}
+
+		if !equality.Semantic.DeepEqual(existing.Spec, weighted.Spec) {
+			return nil, fmt.Errorf("another path of canary Ingress %s already splits backend %q of primary Ingress %s "+
+				"with a different canary backend", canary.Ingress.Name, primaryService.Name, primary.Ingress.Name)
+		}
+
+		return existing, nil
+	}
+
+	primary.Result.TraefikServices = append(primary.Result.TraefikServices, weighted)
+
+	return weighted, nil
+}
+
+// parseCanary reads the canary annotations of ctx.
+func parseCanary(ctx configs.Context) (canarySpec, error) {
+	spec := canarySpec{
+		weightTotal:   defaultCanaryWeightTotal,
+		header:        strings.TrimSpace(ctx.Annotations[string(models.CanaryByHeader)]),
+		headerValue:   strings.TrimSpace(ctx.Annotations[string(models.CanaryByHeaderValue)]),
+		headerPattern: strings.TrimSpace(ctx.Annotations[string(models.CanaryByHeaderPattern)]),
+		cookie:        strings.TrimSpace(ctx.Annotations[string(models.CanaryByCookie)]),
+	}
+
+	var err error
+
+	if val, ok := ctx.Annotations[string(models.CanaryWeight)]; ok {
+		if spec.weight, err = strconv.Atoi(strings.TrimSpace(val)); err != nil || spec.weight < 0 {
+			return spec, fmt.Errorf("invalid canary-weight %q", val)
+		}
+	}
+
+	if val, ok := ctx.Annotations[string(models.CanaryWeightTotal)]; ok {
+		if spec.weightTotal, err = strconv.Atoi(strings.TrimSpace(val)); err != nil || spec.weightTotal <= 0 {
+			return spec, fmt.Errorf("invalid canary-weight-total %q", val)
+		}
+	}
+
+	if spec.headerPattern != "" && spec.headerValue == "" {
+		if _, err = regexp.Compile(spec.headerPattern); err != nil {
+			return spec, fmt.Errorf("canary-by-header-pattern %q is not a valid Go regular expression: %w", spec.headerPattern, err)
+		}
+	}
+
+	return spec, nil
+}
+
+// reportCanary reports every canary annotation set on the Ingress.
+func reportCanary(ctx *configs.Context, report func(name, msg string), msg string) {
+	for _, ann := range models.CanaryAnnotations {
+		if _, ok := ctx.Annotations[string(ann)]; ok {
+			report(string(ann), msg)
+		}
+	}
+}
+
+// forEachBackendPath calls fn for every path of the Ingress backed by a Service.
+func forEachBackendPath(ingress *netv1.Ingress, fn func(host string, path netv1.HTTPIngressPath)) {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				fn(rule.Host, path)
+			}
+		}
+	}
+}
+
+func canaryKey(namespace, host, path string) string {
+	if path == "" {
+		path = "/"
+	}
+
+	return namespace + "|" + host + "|" + path
+}
@@ -11,10 +11,11 @@ type middlewareCategory int
 
 const (
 	catShortCircuit     middlewareCategory = iota // A: return-status plugin (future)
-	catResponseHeaders                            // B: CORS, headers, cookie rewrites, upstream-vhost
+	catResponseHeaders                            // B: CORS, headers, cookie rewrites, upstream-vhost, auth-signin errors
 	catAuth                                       // C: BasicAuth, ForwardAuth
 	catRequestTransform                           // D: rewrite, redirect, bodysize, proxy-redirect
 	catOther                                      // E: fallback
+	catRetry                                      // F: retry, innermost so only the backend call is retried
 )
 
 func classifyMiddleware(middleware *traefik.Middleware) middlewareCategory {
@@ -25,8 +26,14 @@ func classifyMiddleware(middleware *traefik.Middleware) middlewareCategory {
 	case strings.Contains(name, "conditional-return"):
 		return catShortCircuit
 
-	// B: response header injectors
+	// F: retries must not replay the auth or any other middleware
+	case middleware.Spec.Retry != nil:
+		return catRetry
+
+	// B: response header injectors, and the Errors middlewares which must
+	// wrap the auth middlewares to catch their 401s
 	case middleware.Spec.Headers != nil,
+		middleware.Spec.Errors != nil,
 		strings.Contains(name, "cors"),
 		strings.Contains(name, "configuration-snippet"),
 		strings.Contains(name, "proxy-cookie"),
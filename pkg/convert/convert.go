@@ -10,6 +10,7 @@ import (
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/middleware"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/tls"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/transport"
 )
 
 // Run processes ingress annotations using the available converters.
@@ -20,6 +21,13 @@ func Run(ctx configs.Context) error {
 		warnHelmManagedIngress(ctx)
 	}
 
+	// Canary Ingresses are merged into their primary Ingress by Canaries.
+	if IsCanary(ctx) {
+		runCanary(ctx)
+
+		return nil
+	}
+
 	if err := middleware.CORS(ctx); err != nil {
 		return err
 	}
@@ -35,7 +43,6 @@ func Run(ctx configs.Context) error {
 		return err
 	}
 
-	middleware.RewriteTargets(ctx)
 	middleware.AppRoot(ctx)
 	middleware.PermanentRedirect(ctx)
 
@@ -59,17 +66,29 @@ func Run(ctx configs.Context) error {
 	middleware.ServerSnippet(ctx)
 	middleware.EnableUnderscoresInHeaders(ctx)
 	middleware.ExtraAnnotations(ctx)
+
+	if err := middleware.HSTS(ctx); err != nil {
+		return err
+	}
+
 	middleware.ProxyBuffering(ctx)
 	middleware.HandleAuthURL(ctx)
-	middleware.ProxyTimeouts(ctx)
+	middleware.Retry(ctx)
+	middleware.CustomHTTPErrors(ctx)
 	middleware.WhitelistSourceRange(ctx)
 
+	// ServersTransports must exist before BuildIngressRoute references them.
+	transport.ProxySSL(ctx)
+	transport.ProxyTimeouts(ctx)
+
 	sortMiddlewares(ctx.Result.Middlewares)
 
 	if err := ingressroute.BuildIngressRoute(ctx); err != nil {
 		ctx.Result.Warnings = append(ctx.Result.Warnings, err.Error())
 	}
 
+	ingressroute.ReportAffinity(ctx)
+
 	middleware.SSLRedirect(ctx) // must run after BuildIngressRoute
 
 	tls.HandleAuthTLSVerifyClient(ctx)
@@ -78,7 +97,7 @@ func Run(ctx configs.Context) error {
 	if ctx.Options.CopyCertificates {
 		certificate.ExtractOrGenerate(ctx)
 	}
-	
+
 	// Warn about any nginx.ingress.kubernetes.io/* annotations that are
 	// present on the Ingress but not recognised by the converter.
 	warnUnknownAnnotations(ctx)
@@ -109,14 +128,13 @@ var ignoredPrefixes = []string{
 	"fluxcd.io/",
 }
 
-// warnUnknownAnnotations reports any nginx.ingress.kubernetes.io/* annotation
-// that is not in the converter's known list.
+// warnUnknownAnnotations reports any nginx ingress annotation that is not in
+// the converter's known list. Annotations using a custom prefix have already
+// been normalised to models.Prefix and are reported under their original key.
 func warnUnknownAnnotations(ctx configs.Context) {
-	const nginxPrefix = "nginx.ingress.kubernetes.io/"
-
 	for key := range ctx.Annotations {
 		// Only flag nginx ingress annotations.
-		if !strings.HasPrefix(key, nginxPrefix) {
+		if !strings.HasPrefix(key, models.Prefix) {
 			// Also skip well-known non-nginx prefixes silently.
 			if hasAnyPrefix(key, ignoredPrefixes) {
 				continue
@@ -128,7 +146,7 @@ func warnUnknownAnnotations(ctx configs.Context) {
 		}
 
 		if _, known := knownAnnotations[key]; !known {
-			msg := fmt.Sprintf("annotation %q is not supported by the converter and was ignored", key)
+			msg := fmt.Sprintf("annotation %q is not supported by the converter and was ignored", ctx.OriginalAnnotation(key))
 			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
 			ctx.ReportSkipped(key, msg)
 		}
@@ -0,0 +1,171 @@
+package convert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/ingressroute"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const reportRoutePriority = "route-priority"
+
+// hostPath is a path of an Ingress rule with the priority of its route.
+type hostPath struct {
+	ctx      *configs.Context
+	path     netv1.HTTPIngressPath
+	priority int
+}
+
+// Priorities reviews the route priorities of every Ingress sharing a host.
+// The priorities set by BuildIngressRoute already reproduce nginx's location
+// order across Ingresses; this pass resolves paths defined by several
+// Ingresses, which nginx serves from the oldest one, and reports the order
+// of shared hosts. It must run before Canaries, which builds on the
+// priorities of the primary routes.
+func Priorities(contexts []*configs.Context) {
+	ordered := make([]*configs.Context, 0, len(contexts))
+
+	for _, ctx := range contexts {
+		if ctx.Ingress != nil && !IsCanary(*ctx) && len(ctx.Result.IngressRoutes) != 0 {
+			ordered = append(ordered, ctx)
+		}
+	}
+
+	// ingress-nginx keeps the first definition of a location, reading the
+	// Ingresses from the oldest.
+	sort.SliceStable(ordered, func(i, j int) bool {
+		left, right := ordered[i].Ingress, ordered[j].Ingress
+		if !left.CreationTimestamp.Equal(&right.CreationTimestamp) {
+			return left.CreationTimestamp.Before(&right.CreationTimestamp)
+		}
+
+		return ingressKey(left) < ingressKey(right)
+	})
+
+	hosts := make([]string, 0)
+	byHost := make(map[string][]hostPath)
+	owners := make(map[string]*configs.Context)
+
+	for _, ctx := range ordered {
+		for _, rule := range ctx.Ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service == nil {
+					continue
+				}
+
+				priority := ingressroute.RoutePriority(rule.Host, path)
+
+				key := fmt.Sprintf("%s|%s|%s", rule.Host, path.Path, pathTypeOf(path))
+				if owner, ok := owners[key]; ok && owner != ctx {
+					priority = lowerRoutes(ctx, rule.Host, path)
+
+					msg := fmt.Sprintf("path %q of host %q is also defined by the older Ingress %s, which nginx serves; "+
+						"the route priority was lowered to %d so Traefik does the same",
+						path.Path, rule.Host, ingressKey(owner.Ingress), priority)
+
+					ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+					ctx.ReportWarning(reportRoutePriority, msg)
+				} else if !ok {
+					owners[key] = ctx
+				}
+
+				if _, ok := byHost[rule.Host]; !ok {
+					hosts = append(hosts, rule.Host)
+				}
+
+				byHost[rule.Host] = append(byHost[rule.Host], hostPath{ctx: ctx, path: path, priority: priority})
+			}
+		}
+	}
+
+	shared := make(map[*configs.Context]bool)
+
+	for _, host := range hosts {
+		paths := byHost[host]
+		if !sharedHost(paths) {
+			continue
+		}
+
+		sort.SliceStable(paths, func(i, j int) bool { return paths[i].priority > paths[j].priority })
+
+		order := make([]string, 0, len(paths))
+		for _, hp := range paths {
+			order = append(order, fmt.Sprintf("%s %q of %s: %d", pathTypeOf(hp.path), hp.path.Path, ingressKey(hp.ctx.Ingress), hp.priority))
+		}
+
+		msg := fmt.Sprintf("host %q is shared by several Ingresses; route priorities reproduce nginx's location order: %s",
+			displayHost(host), strings.Join(order, ", "))
+
+		reported := make(map[*configs.Context]bool)
+
+		for _, hp := range paths {
+			if !reported[hp.ctx] {
+				reported[hp.ctx] = true
+				shared[hp.ctx] = true
+
+				hp.ctx.ReportWarning(reportRoutePriority, msg)
+			}
+		}
+	}
+
+	for _, ctx := range ordered {
+		if !shared[ctx] {
+			ctx.ReportConverted(reportRoutePriority)
+		}
+	}
+}
+
+// lowerRoutes lowers the priority of the routes serving path on host, in
+// every IngressRoute of ctx, and returns the new priority.
+func lowerRoutes(ctx *configs.Context, host string, path netv1.HTTPIngressPath) int {
+	match := ingressroute.RouteMatch(*ctx, host, path)
+	priority := ingressroute.ShadowedPriority(ingressroute.RoutePriority(host, path))
+
+	for _, ingressRoute := range ctx.Result.IngressRoutes {
+		for i := range ingressRoute.Spec.Routes {
+			if ingressRoute.Spec.Routes[i].Match == match {
+				ingressRoute.Spec.Routes[i].Priority = priority
+			}
+		}
+	}
+
+	return priority
+}
+
+// sharedHost reports whether the paths come from more than one Ingress.
+func sharedHost(paths []hostPath) bool {
+	for _, hp := range paths[1:] {
+		if hp.ctx != paths[0].ctx {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathTypeOf(path netv1.HTTPIngressPath) netv1.PathType {
+	if path.PathType == nil {
+		return netv1.PathTypePrefix
+	}
+
+	return *path.PathType
+}
+
+func ingressKey(ing *netv1.Ingress) string {
+	return ing.Namespace + "/" + ing.Name
+}
+
+func displayHost(host string) string {
+	if host == "" {
+		return "*"
+	}
+
+	return host
+}
@@ -0,0 +1,191 @@
+package ingressroute
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// defaultSessionCookieName is the ingress-nginx default for session-cookie-name.
+	defaultSessionCookieName = "INGRESSCOOKIE"
+
+	affinityCookie     = "cookie"
+	affinityBalanced   = "balanced"
+	affinityPersistent = "persistent"
+)
+
+// ReportAffinity handles the below annotations, the sticky cookie itself is
+// set on every service of the IngressRoute by Sticky.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/affinity"
+//   - "nginx.ingress.kubernetes.io/affinity-mode"
+//   - "nginx.ingress.kubernetes.io/session-cookie-name"
+//   - "nginx.ingress.kubernetes.io/session-cookie-path"
+//   - "nginx.ingress.kubernetes.io/session-cookie-samesite"
+//   - "nginx.ingress.kubernetes.io/session-cookie-secure"
+//   - "nginx.ingress.kubernetes.io/session-cookie-max-age"
+//   - "nginx.ingress.kubernetes.io/session-cookie-expires"
+//   - "nginx.ingress.kubernetes.io/session-cookie-change-on-failure"
+func ReportAffinity(ctx configs.Context) {
+	ctx.Log.Debug("running converter SessionAffinity")
+
+	annAffinity := string(models.Affinity)
+
+	reportSet := func(report func(name, msg string), msg string, anns ...models.Annotation) {
+		for _, ann := range anns {
+			if _, ok := ctx.Annotations[string(ann)]; ok {
+				report(string(ann), msg)
+			}
+		}
+	}
+
+	affinity, ok := ctx.Annotations[annAffinity]
+	if !ok {
+		reportSet(ctx.ReportIgnored, "affinity is not set", models.AffinityAnnotations...)
+
+		return
+	}
+
+	if strings.TrimSpace(affinity) != affinityCookie {
+		msg := fmt.Sprintf("affinity %q is not supported, only cookie affinity can be converted", affinity)
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annAffinity, msg)
+		reportSet(ctx.ReportIgnored, msg, models.AffinityAnnotations[1:]...)
+
+		return
+	}
+
+	if _, err := stickyCookie(ctx.Annotations); err != nil {
+		ctx.Result.Warnings = append(ctx.Result.Warnings, err.Error())
+		reportSet(ctx.ReportSkipped, err.Error(), models.AffinityAnnotations...)
+
+		return
+	}
+
+	reportSet(func(name, _ string) { ctx.ReportConverted(name) }, "",
+		models.Affinity,
+		models.SessionCookieName,
+		models.SessionCookieSameSite,
+		models.SessionCookieSecure,
+		models.SessionCookieMaxAge,
+	)
+
+	annPath := string(models.SessionCookiePath)
+	if _, ok := ctx.Annotations[annPath]; ok {
+		ctx.ReportConverted(annPath)
+	} else if strings.ToLower(ctx.Annotations[string(models.UseRegex)]) == "true" {
+		msg := "use-regex is set without session-cookie-path; the sticky cookie path defaults to '/' in Traefik"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(annAffinity, msg)
+	}
+
+	annExpires := string(models.SessionCookieExpires)
+	if _, ok := ctx.Annotations[annExpires]; ok {
+		if _, ok := ctx.Annotations[string(models.SessionCookieMaxAge)]; ok {
+			ctx.ReportIgnored(annExpires, "session-cookie-max-age takes precedence, Traefik sticky cookies only set Max-Age")
+		} else {
+			ctx.ReportWarning(annExpires, "converted to the sticky cookie maxAge, Traefik sets Max-Age instead of Expires")
+		}
+	}
+
+	annMode := string(models.AffinityMode)
+	if mode, ok := ctx.Annotations[annMode]; ok {
+		switch strings.TrimSpace(mode) {
+		case affinityBalanced:
+			ctx.ReportConverted(annMode)
+		case affinityPersistent:
+			msg := "affinity-mode persistent has no Traefik equivalent: Traefik keeps a session on its server " +
+				"while the server is healthy but moves it when the server goes away, verify the behaviour"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annMode, msg)
+		default:
+			ctx.ReportSkipped(annMode, fmt.Sprintf("unknown affinity-mode %q", mode))
+		}
+	}
+
+	annChange := string(models.SessionCookieChangeOnFailure)
+	if _, ok := ctx.Annotations[annChange]; ok {
+		msg := "session-cookie-change-on-failure has no Traefik equivalent: Traefik always picks a new server " +
+			"and rewrites the sticky cookie when the sticky server is unavailable"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(annChange, msg)
+	}
+}
+
+// Sticky returns the sticky cookie configuration for a service serving path,
+// or nil when cookie affinity is not enabled. As in nginx, the cookie path
+// defaults to the Ingress path unless the path is a regular expression.
+func Sticky(ctx configs.Context, path netv1.HTTPIngressPath) *dynamic.Sticky {
+	cookie, err := stickyCookie(ctx.Annotations)
+	if err != nil || cookie == nil {
+		return nil
+	}
+
+	useRegex := strings.ToLower(ctx.Annotations[string(models.UseRegex)]) == "true"
+	if cookie.Path == nil && path.Path != "" && !useRegex && !looksLikeRegex(path.Path) {
+		cookiePath := path.Path
+		cookie.Path = &cookiePath
+	}
+
+	return &dynamic.Sticky{Cookie: cookie}
+}
+
+// stickyCookie builds the cookie from the session affinity annotations. It
+// returns nil when affinity is not "cookie".
+func stickyCookie(annotations map[string]string) (*dynamic.Cookie, error) {
+	if strings.TrimSpace(annotations[string(models.Affinity)]) != affinityCookie {
+		return nil, nil //nolint:nilnil
+	}
+
+	cookie := &dynamic.Cookie{
+		Name: defaultSessionCookieName,
+		// ingress-nginx always marks the session cookie HttpOnly.
+		HTTPOnly: true,
+		Secure:   strings.TrimSpace(annotations[string(models.SessionCookieSecure)]) == "true",
+	}
+
+	if name := strings.TrimSpace(annotations[string(models.SessionCookieName)]); name != "" {
+		cookie.Name = name
+	}
+
+	if path := strings.TrimSpace(annotations[string(models.SessionCookiePath)]); path != "" {
+		cookie.Path = &path
+	}
+
+	if sameSite := strings.TrimSpace(annotations[string(models.SessionCookieSameSite)]); sameSite != "" {
+		switch strings.ToLower(sameSite) {
+		case "none", "lax", "strict":
+			cookie.SameSite = strings.ToLower(sameSite)
+		default:
+			return nil, fmt.Errorf("invalid session-cookie-samesite %q, expected None, Lax or Strict", sameSite)
+		}
+	}
+
+	for _, ann := range []models.Annotation{models.SessionCookieMaxAge, models.SessionCookieExpires} {
+		val, ok := annotations[string(ann)]
+		if !ok {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected seconds", strings.TrimPrefix(string(ann), models.Prefix), val)
+		}
+
+		cookie.MaxAge = seconds
+
+		break
+	}
+
+	return cookie, nil
+}
@@ -0,0 +1,110 @@
+package ingressroute
+
+import (
+	"fmt"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// defaultBackendPriority is the lowest explicit Traefik priority within a
+	// host tier, so the catch-all route of spec.defaultBackend never shadows
+	// another route of its host.
+	defaultBackendPriority = 1
+
+	reportDefaultBackend = "spec.defaultBackend"
+	reportResource       = "resource-backend"
+)
+
+// defaultBackendRoutes returns the catch-all routes serving
+// spec.defaultBackend. As in nginx, it receives the requests of every host of
+// the Ingress that no path matches, or every request when the Ingress has no
+// rules. Hosts already routing "/" are left out.
+func defaultBackendRoutes(ctx configs.Context, scheme string, rootCovered map[string]bool) []hostRoute {
+	backend := ctx.Ingress.Spec.DefaultBackend
+	if backend == nil {
+		return nil
+	}
+
+	if backend.Service == nil {
+		reportResourceBackend(ctx, reportDefaultBackend, backend.Resource)
+
+		return nil
+	}
+
+	hosts := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, rule := range ctx.Ingress.Spec.Rules {
+		if _, ok := seen[rule.Host]; ok || rootCovered[rule.Host] {
+			continue
+		}
+
+		seen[rule.Host] = struct{}{}
+		hosts = append(hosts, rule.Host)
+	}
+
+	if len(ctx.Ingress.Spec.Rules) == 0 {
+		hosts = append(hosts, "")
+	}
+
+	if len(hosts) == 0 {
+		ctx.ReportIgnored(reportDefaultBackend, "every host of the Ingress already routes /")
+
+		return nil
+	}
+
+	root := netv1.HTTPIngressPath{Path: "/"}
+	routes := make([]hostRoute, 0, len(hosts))
+
+	for _, host := range hosts {
+		service := backendService(ctx, backend.Service, scheme)
+		service.Sticky = Sticky(ctx, root)
+
+		routes = append(routes, hostRoute{
+			host: host,
+			route: traefik.Route{
+				Kind:        "Rule",
+				Match:       combineMatch(buildHostMatch(host), "PathPrefix(`/`)"),
+				Priority:    hostPriority(host) + defaultBackendPriority,
+				Services:    []traefik.Service{service},
+				Middlewares: middlewareRefs(ctx),
+			},
+		})
+	}
+
+	ctx.ReportConverted(reportDefaultBackend)
+
+	return routes
+}
+
+// reportResourceBackend records a Resource backend, which has no Traefik
+// equivalent, as skipped.
+func reportResourceBackend(ctx configs.Context, where string, resource *corev1.TypedLocalObjectReference) {
+	if resource == nil {
+		return
+	}
+
+	kind := resource.Kind
+	if resource.APIGroup != nil && *resource.APIGroup != "" {
+		kind = resource.Kind + "." + *resource.APIGroup
+	}
+
+	msg := fmt.Sprintf("%s references resource %s %s/%s, Resource backends cannot be converted to Traefik",
+		where, kind, ctx.Namespace, resource.Name)
+
+	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+	ctx.ReportSkipped(reportResource, msg)
+}
+
+// coversRoot reports whether path already routes every request of its host.
+func coversRoot(path netv1.HTTPIngressPath, useRegex bool) bool {
+	if path.PathType != nil && *path.PathType == netv1.PathTypeExact {
+		return false
+	}
+
+	return path.Path == "" || path.Path == "/" || (useRegex && path.Path == "/.*")
+}
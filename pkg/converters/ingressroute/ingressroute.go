@@ -7,11 +7,9 @@ import (
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
-	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/tls"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/transport"
 	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
-	traefiktypes "github.com/traefik/traefik/v3/pkg/types"
 	netv1 "k8s.io/api/networking/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -19,6 +17,7 @@ import (
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/backend-protocol"
 //   - "nginx.ingress.kubernetes.io/grpc-backend"
+//   - "nginx.ingress.kubernetes.io/rewrite-target"
 //   - "nginx.ingress.kubernetes.io/use-regex"
 func BuildIngressRoute(ctx configs.Context) error {
 	ing := ctx.Ingress
@@ -30,9 +29,14 @@ func BuildIngressRoute(ctx configs.Context) error {
 	}
 
 	useRegex := strings.ToLower(ctx.Annotations[string(models.UseRegex)]) == "true"
+	strict := strictPrefix(ctx)
+	elementPaths := make([]string, 0)
+	rewrites := newRewriteTargets(ctx)
+	regexSkipped := false
 
-	routes := make([]traefik.Route, 0)
+	routes := make([]hostRoute, 0)
 	seen := make(map[string]struct{}) // dedup key set
+	rootCovered := make(map[string]bool)
 
 	for _, rule := range ing.Spec.Rules {
 		if rule.HTTP == nil {
@@ -53,17 +57,25 @@ func BuildIngressRoute(ctx configs.Context) error {
 		for _, path := range rule.HTTP.Paths {
 			svc := path.Backend.Service
 			if svc == nil {
+				reportResourceBackend(ctx, fmt.Sprintf("path %q of host %q", path.Path, rule.Host), path.Backend.Resource)
+
 				continue
 			}
 
-			pathMatch, regexPromoted := buildPathMatch(path, useRegex)
+			if useRegex && !regexPathConverts(ctx, path, rule.Host) {
+				regexSkipped = true
 
-			// Warn when use-regex was set but the regex is invalid.
-			if useRegex && !regexPromoted && looksLikeRegex(path.Path) {
-				msg := fmt.Sprintf("use-regex is set but path '%s' is not a valid Go regex for Traefik; fell back to PathPrefix", path.Path)
+				continue
+			}
 
-				ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
-				ctx.ReportWarning(string(models.UseRegex), msg)
+			if coversRoot(path, useRegex) {
+				rootCovered[rule.Host] = true
+			}
+
+			pathMatch, regexPromoted := buildPathMatch(path, useRegex, strict)
+
+			if strict && isElementPrefix(path, useRegex) {
+				elementPaths = append(elementPaths, fmt.Sprintf("%q of host %q", path.Path, displayHost(rule.Host)))
 			}
 
 			// Warn when path was heuristically promoted to PathRegexp.
@@ -118,64 +130,89 @@ func BuildIngressRoute(ctx configs.Context) error {
 
 			seen[key] = struct{}{}
 
+			service := backendService(ctx, svc, scheme)
+			service.Sticky = Sticky(ctx, path)
+
 			route := traefik.Route{
-				Kind:  "Rule",
-				Match: match,
-				Services: []traefik.Service{
-					{
-						LoadBalancerSpec: traefik.LoadBalancerSpec{
-							Name:   svc.Name,
-							Port:   svcPort,
-							Scheme: scheme,
-						},
-					},
-				},
+				Kind:        "Rule",
+				Match:       match,
+				Priority:    RoutePriority(rule.Host, path),
+				Services:    []traefik.Service{service},
 				Middlewares: middlewareRefs(ctx),
 			}
 
-			routes = append(routes, route)
+			if rewrites != nil {
+				if ref, ok := rewrites.ref(ctx, path, rule.Host); ok {
+					route.Middlewares = insertBeforeRetry(ctx, route.Middlewares, ref)
+				}
+			}
+
+			routes = append(routes, hostRoute{host: rule.Host, route: route})
 		}
 	}
 
+	routes = append(routes, defaultBackendRoutes(ctx, scheme, rootCovered)...)
+
+	// The rewrite middlewares are only added now, so that only their own
+	// route references them.
+	if rewrites != nil {
+		rewrites.finish(ctx)
+	}
+
+	if len(elementPaths) != 0 {
+		msg := fmt.Sprintf("Prefix paths %s match whole path elements as in Kubernetes and nginx, "+
+			"so /foo matches /foo/bar but not /foobar; --loose-prefix-paths converts them to plain PathPrefix matchers",
+			strings.Join(elementPaths, ", "))
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(reportPrefixPaths, msg)
+	}
+
 	if len(routes) == 0 {
 		return nil
 	}
 
-	// EntryPoints are always "web" by default.
-	// Frontend TLS (spec.tls) promotes to "websecure".
-	entryPoints := []string{"web"}
-	if len(ing.Spec.TLS) > 0 {
-		entryPoints = []string{"websecure"}
-	}
+	// Hosts served with different certificates get their own IngressRoute.
+	ctx.Result.IngressRoutes = append(ctx.Result.IngressRoutes, groupIngressRoutes(ctx, groupRoutes(ing, routes))...)
 
-	ingressRoute := &traefik.IngressRoute{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: traefik.SchemeGroupVersion.String(),
-			Kind:       "IngressRoute",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      ing.Name + configs.ConvertedSuffix,
-			Namespace: ing.Namespace,
-		},
-		Spec: traefik.IngressRouteSpec{
-			EntryPoints: entryPoints,
-			Routes:      routes,
-		},
+	// Paths that could not be translated were already reported as skipped.
+	if useRegex && !regexSkipped {
+		ctx.ReportConverted(string(models.UseRegex))
 	}
 
-	// Apply TLS from Ingress spec.tls (standard TLS termination).
-	applyIngressTLS(ingressRoute, ing)
+	return nil
+}
 
-	// Apply mTLS TLS Option if present (may extend TLS section).
-	tls.ApplyTLSOption(ingressRoute, ctx)
+// RouteMatch returns the match expression BuildIngressRoute generates for a
+// path of the rule with the given host, so other passes can find the route
+// serving it.
+func RouteMatch(ctx configs.Context, host string, path netv1.HTTPIngressPath) string {
+	useRegex := strings.ToLower(ctx.Annotations[string(models.UseRegex)]) == "true"
+	pathMatch, _ := buildPathMatch(path, useRegex, strictPrefix(ctx))
 
-	ctx.Result.IngressRoutes = append(ctx.Result.IngressRoutes, ingressRoute)
+	return combineMatch(buildHostMatch(host), pathMatch)
+}
 
-	if useRegex {
-		ctx.ReportConverted(string(models.UseRegex))
+// BackendService returns the Traefik service for an Ingress backend, using
+// the backend protocol of the Ingress in ctx.
+func BackendService(ctx configs.Context, svc *netv1.IngressServiceBackend) (traefik.Service, error) {
+	scheme, err := resolveScheme(ctx.Annotations)
+	if err != nil {
+		return traefik.Service{}, err
 	}
 
-	return nil
+	return backendService(ctx, svc, scheme), nil
+}
+
+func backendService(ctx configs.Context, svc *netv1.IngressServiceBackend, scheme string) traefik.Service {
+	return traefik.Service{
+		LoadBalancerSpec: traefik.LoadBalancerSpec{
+			Name:             svc.Name,
+			Port:             buildServicePort(svc.Port),
+			Scheme:           scheme,
+			ServersTransport: transport.Name(ctx),
+		},
+	}
 }
 
 // buildServicePort returns an IntOrString for the Traefik service port.
@@ -193,36 +230,6 @@ func buildServicePort(port netv1.ServiceBackendPort) intstr.IntOrString {
 	return intstr.FromInt32(0)
 }
 
-func applyIngressTLS(ingressRoute *traefik.IngressRoute, ing *netv1.Ingress) {
-	if len(ing.Spec.TLS) == 0 {
-		return
-	}
-
-	if ingressRoute.Spec.TLS == nil {
-		ingressRoute.Spec.TLS = &traefik.TLS{}
-	}
-
-	// Use the first TLS entry's secretName.
-	for _, t := range ing.Spec.TLS {
-		if t.SecretName != "" {
-			ingressRoute.Spec.TLS.SecretName = t.SecretName
-
-			break
-		}
-	}
-
-	// Add wildcard domains to TLS.Domains so Traefik can match SNI
-	for _, rule := range ing.Spec.Rules {
-		if isWildcardHost(rule.Host) {
-			ingressRoute.Spec.TLS.Domains = append(ingressRoute.Spec.TLS.Domains,
-				traefiktypes.Domain{
-					Main: rule.Host,
-				},
-			)
-		}
-	}
-}
-
 // middlewareRefs builds MiddlewareRef entries from the already-sorted
 // Result.Middlewares slice (sorted by classify_middleware.go).
 func middlewareRefs(ctx configs.Context) []traefik.MiddlewareRef {
@@ -235,6 +242,18 @@ func middlewareRefs(ctx configs.Context) []traefik.MiddlewareRef {
 	return refs
 }
 
+// insertBeforeRetry adds ref to the middlewares of a route, before the Retry
+// middleware, which must stay the innermost.
+func insertBeforeRetry(ctx configs.Context, refs []traefik.MiddlewareRef, ref traefik.MiddlewareRef) []traefik.MiddlewareRef {
+	for i, mw := range ctx.Result.Middlewares {
+		if mw.Spec.Retry != nil && i < len(refs) {
+			return append(refs[:i], append([]traefik.MiddlewareRef{ref}, refs[i:]...)...)
+		}
+	}
+
+	return append(refs, ref)
+}
+
 func isWildcardHost(host string) bool {
 	return strings.HasPrefix(host, "*.")
 }
@@ -281,16 +300,21 @@ func looksLikeRegex(path string) bool {
 
 // buildPathMatch produces the Traefik match expression for a single path.
 // The second return value (regexPromoted) is true when the path was
-// heuristically promoted from PathPrefix to PathRegexp.
-func buildPathMatch(path netv1.HTTPIngressPath, useRegex bool) (match string, regexPromoted bool) {
+// heuristically promoted from PathPrefix to PathRegexp. With strict, Prefix
+// paths match whole path elements, see elementPrefixMatch.
+func buildPathMatch(path netv1.HTTPIngressPath, useRegex, strict bool) (match string, regexPromoted bool) {
 	pth := path.Path
 	if pth == "" {
 		pth = "/"
 	}
 
-	// Explicit use-regex annotation: try to compile as Go regex.
+	// Explicit use-regex annotation: translate the PCRE location to RE2.
 	if useRegex {
-		return buildRegexpMatch(pth)
+		if regex, _, err := translatePCRE(pth); err == nil {
+			return fmt.Sprintf("PathRegexp(`%s`)", regex), true
+		}
+
+		return fmt.Sprintf("PathPrefix(`%s`)", pth), false
 	}
 
 	// Heuristic: detect regex metacharacters even when use-regex is absent.
@@ -316,6 +340,10 @@ func buildPathMatch(path netv1.HTTPIngressPath, useRegex bool) (match string, re
 	case netv1.PathTypeExact:
 		return fmt.Sprintf("Path(`%s`)", pth), false
 	case netv1.PathTypePrefix:
+		if strict {
+			return elementPrefixMatch(pth), false
+		}
+
 		return fmt.Sprintf("PathPrefix(`%s`)", pth), false
 	case netv1.PathTypeImplementationSpecific:
 		return fmt.Sprintf("PathPrefix(`%s`)", pth), false
@@ -0,0 +1,319 @@
+package ingressroute
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// re2Flags are the inline flags RE2 shares with PCRE.
+const re2Flags = "imsU-"
+
+// translatePCRE rewrites the PCRE path of a use-regex location into the RE2
+// syntax of Traefik's PathRegexp. ingress-nginx generates case-insensitive
+// locations anchored at the start of the path (location ~* "^path"), so the
+// result is prefixed with (?i)^. Constructs RE2 can express differently are
+// rewritten, and the notes describe rewrites that may match more paths than
+// nginx does. Constructs without an RE2 equivalent, such as lookarounds and
+// backreferences, are returned as an error naming each of them.
+func translatePCRE(pattern string) (string, []string, error) {
+	var (
+		out         strings.Builder
+		notes       []string
+		unsupported []string
+	)
+
+	for i := 0; i < len(pattern); {
+		switch c := pattern[i]; c {
+		case '\\':
+			next := i + 2
+			if i+1 >= len(pattern) {
+				unsupported = append(unsupported, "a trailing \\")
+				i++
+
+				continue
+			}
+
+			switch e := pattern[i+1]; {
+			case e >= '1' && e <= '9':
+				unsupported = append(unsupported, fmt.Sprintf("backreference \\%c at offset %d", e, i))
+			case e == 'k' || e == 'g':
+				end := escapeReferenceEnd(pattern, i+2)
+				unsupported = append(unsupported, fmt.Sprintf("backreference %s at offset %d", pattern[i:end], i))
+				next = end
+			case e == 'Z':
+				// Paths never end with a newline, so \Z is the end of the text.
+				out.WriteString(`\z`)
+			case e == 'h':
+				out.WriteString(`[\t ]`)
+			case e == 'H':
+				out.WriteString(`[^\t ]`)
+			case e == 'Q':
+				// RE2 supports quoting, copy it whole so the quoted text is not
+				// read as PCRE.
+				end := strings.Index(pattern[i:], `\E`)
+				if end < 0 {
+					next = len(pattern)
+				} else {
+					next = i + end + 2
+				}
+
+				out.WriteString(pattern[i:next])
+			default:
+				out.WriteString(pattern[i:next])
+			}
+
+			i = next
+		case '[':
+			end := classEnd(pattern, i)
+			out.WriteString(pattern[i:end])
+			i = end
+		case '(':
+			next, rewritten, msg, ok := translateGroup(pattern, i)
+			if !ok {
+				unsupported = append(unsupported, msg)
+			} else if msg != "" {
+				notes = append(notes, msg)
+			}
+
+			out.WriteString(rewritten)
+			i = next
+		case '*', '+', '?', '{':
+			end := i + 1
+			if c == '{' {
+				end = repeatEnd(pattern, i)
+			}
+
+			out.WriteString(pattern[i:end])
+
+			// A brace that does not open a repeat is a literal.
+			isQuantifier := c != '{' || end > i+1
+
+			switch {
+			case !isQuantifier || end >= len(pattern):
+			case pattern[end] == '+':
+				notes = append(notes, fmt.Sprintf("possessive quantifier %s at offset %d rewritten as greedy",
+					pattern[i:end+1], i))
+				end++
+			case pattern[end] == '?':
+				out.WriteByte('?')
+				end++
+			}
+
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	translated := out.String()
+	if !strings.HasPrefix(translated, "^") {
+		translated = "^" + translated
+	}
+
+	translated = "(?i)" + translated
+
+	if len(unsupported) == 0 {
+		if _, err := regexp.Compile(translated); err != nil {
+			unsupported = append(unsupported, err.Error())
+		}
+	}
+
+	if len(unsupported) != 0 {
+		return "", nil, &errors.ConverterError{Message: fmt.Sprintf("regex path %q cannot be converted to RE2: %s",
+			pattern, strings.Join(unsupported, "; "))}
+	}
+
+	return translated, notes, nil
+}
+
+// translateGroup rewrites the group opening at pattern[start]. It returns the
+// offset after the group prefix, its RE2 form, and either a note on the
+// rewrite or, with ok false, the reason it has no RE2 equivalent.
+func translateGroup(pattern string, start int) (next int, rewritten, msg string, ok bool) {
+	rest := pattern[start:]
+
+	unsupported := func(kind, prefix string) (int, string, string, bool) {
+		return start + len(prefix), "(", fmt.Sprintf("%s %s at offset %d", kind, prefix, start), false
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "(*"):
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			end = len(rest) - 1
+		}
+
+		return start + end + 1, "", fmt.Sprintf("verb %s at offset %d", rest[:end+1], start), false
+	case !strings.HasPrefix(rest, "(?"):
+		return start + 1, "(", "", true
+	case strings.HasPrefix(rest, "(?="):
+		return unsupported("lookahead", "(?=")
+	case strings.HasPrefix(rest, "(?!"):
+		return unsupported("negative lookahead", "(?!")
+	case strings.HasPrefix(rest, "(?<="):
+		return unsupported("lookbehind", "(?<=")
+	case strings.HasPrefix(rest, "(?<!"):
+		return unsupported("negative lookbehind", "(?<!")
+	case strings.HasPrefix(rest, "(?|"):
+		return unsupported("branch reset group", "(?|")
+	case strings.HasPrefix(rest, "(?P="):
+		return unsupported("backreference", rest[:groupNameEnd(rest, 4)])
+	case strings.HasPrefix(rest, "(?P>"), strings.HasPrefix(rest, "(?&"), strings.HasPrefix(rest, "(?R"),
+		len(rest) > 2 && (rest[2] >= '0' && rest[2] <= '9' || rest[2] == '+'):
+		return unsupported("recursion", rest[:groupNameEnd(rest, 2)])
+	case strings.HasPrefix(rest, "(?>"):
+		return start + 3, "(?:", fmt.Sprintf("atomic group at offset %d rewritten as a plain group", start), true
+	case strings.HasPrefix(rest, "(?#"):
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			end = len(rest) - 1
+		}
+
+		return start + end + 1, "", "", true
+	case strings.HasPrefix(rest, "(?'"):
+		end := strings.IndexByte(rest[3:], '\'')
+		if end < 0 {
+			return start + 3, "(", "", true
+		}
+
+		return start + 3 + end + 1, "(?<" + rest[3:3+end] + ">", "", true
+	case strings.HasPrefix(rest, "(?<"), strings.HasPrefix(rest, "(?P<"):
+		return start + 2, "(?", "", true
+	}
+
+	// Inline flags, either (?flags) or (?flags:...).
+	end := 2
+	for end < len(rest) && rest[end] != ')' && rest[end] != ':' {
+		if !strings.ContainsRune(re2Flags, rune(rest[end])) {
+			return unsupported("flag", rest[:end+1])
+		}
+
+		end++
+	}
+
+	return start + 2, "(?", "", true
+}
+
+// escapeReferenceEnd returns the end of the name or number of a \k or \g
+// reference starting at pattern[start].
+func escapeReferenceEnd(pattern string, start int) int {
+	if start >= len(pattern) {
+		return start
+	}
+
+	closers := map[byte]byte{'<': '>', '{': '}', '\'': '\''}
+	if closer, ok := closers[pattern[start]]; ok {
+		if end := strings.IndexByte(pattern[start+1:], closer); end >= 0 {
+			return start + 1 + end + 1
+		}
+
+		return len(pattern)
+	}
+
+	end := start
+	if pattern[end] == '-' || pattern[end] == '+' {
+		end++
+	}
+
+	for end < len(pattern) && pattern[end] >= '0' && pattern[end] <= '9' {
+		end++
+	}
+
+	return end
+}
+
+// groupNameEnd returns the length of the group prefix of rest up to and
+// including its closing parenthesis, looking from offset from.
+func groupNameEnd(rest string, from int) int {
+	if end := strings.IndexByte(rest[from:], ')'); end >= 0 {
+		return from + end + 1
+	}
+
+	return len(rest)
+}
+
+// classEnd returns the offset after the character class opening at
+// pattern[start]. A ] right after [ or [^ is a literal.
+func classEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+
+	for i < len(pattern) {
+		switch pattern[i] {
+		case '\\':
+			i += 2
+
+			continue
+		case '[':
+			// POSIX classes such as [:alpha:] hold a ].
+			if end := strings.Index(pattern[i:], ":]"); strings.HasPrefix(pattern[i:], "[:") && end >= 0 {
+				i += end + 2
+
+				continue
+			}
+		case ']':
+			return i + 1
+		}
+
+		i++
+	}
+
+	return len(pattern)
+}
+
+// repeatEnd returns the offset after the {n}, {n,} or {n,m} quantifier
+// opening at pattern[start], or start+1 when the brace is a literal.
+func repeatEnd(pattern string, start int) int {
+	if loc := repeatPattern.FindStringIndex(pattern[start:]); loc != nil {
+		return start + loc[1]
+	}
+
+	return start + 1
+}
+
+var repeatPattern = regexp.MustCompile(`^\{\d+(,\d*)?\}`)
+
+// regexPathConverts reports whether the use-regex path translates to RE2. It
+// records why a path gets no route, and the rewrites of approximate
+// translations.
+func regexPathConverts(ctx configs.Context, path netv1.HTTPIngressPath, host string) bool {
+	pth := path.Path
+	if pth == "" {
+		pth = "/"
+	}
+
+	annRegex := string(models.UseRegex)
+
+	_, notes, err := translatePCRE(pth)
+	if err != nil {
+		msg := fmt.Sprintf("%s; no route was generated for host %q, convert the path manually", err.Error(), displayHost(host))
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annRegex, msg)
+
+		return false
+	}
+
+	if len(notes) != 0 {
+		msg := fmt.Sprintf("regex path %q of host %q: %s; the route may match paths nginx does not",
+			path.Path, displayHost(host), strings.Join(notes, ", "))
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(annRegex, msg)
+	}
+
+	return true
+}
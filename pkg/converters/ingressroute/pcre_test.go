@@ -0,0 +1,105 @@
+package ingressroute
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTranslatePCRE(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		want      string
+		wantNotes []string
+	}{
+		{name: "plain path", pattern: `/api/v1/.*`, want: `(?i)^/api/v1/.*`},
+		{name: "anchor is not doubled", pattern: `^/api/.*`, want: `(?i)^/api/.*`},
+		{name: "lazy quantifier kept", pattern: `/a*?`, want: `(?i)^/a*?`},
+		{name: "literal brace", pattern: `/a{x}`, want: `(?i)^/a{x}`},
+		{name: "end of subject", pattern: `/a\Z`, want: `(?i)^/a\z`},
+		{name: "horizontal space", pattern: `/\h+\H`, want: `(?i)^/[\t ]+[^\t ]`},
+		{name: "quoted text is copied", pattern: `/\Q.*+\E`, want: `(?i)^/\Q.*+\E`},
+		{name: "bracket first in a class", pattern: `/[]a]++`, want: `(?i)^/[]a]+`, wantNotes: []string{
+			"possessive quantifier ++ at offset 5 rewritten as greedy",
+		}},
+		{name: "comment group dropped", pattern: `/a(?#c)b`, want: `(?i)^/ab`},
+		{name: "quoted group name", pattern: `/(?'id'\d+)`, want: `(?i)^/(?<id>\d+)`},
+		{name: "python group name", pattern: `/(?P<id>\d+)`, want: `(?i)^/(?P<id>\d+)`},
+		{
+			name:      "possessive star",
+			pattern:   `/a.*+`,
+			want:      `(?i)^/a.*`,
+			wantNotes: []string{"possessive quantifier *+ at offset 3 rewritten as greedy"},
+		},
+		{
+			name:      "possessive repeat",
+			pattern:   `/a{2,3}+b`,
+			want:      `(?i)^/a{2,3}b`,
+			wantNotes: []string{"possessive quantifier {2,3}+ at offset 2 rewritten as greedy"},
+		},
+		{
+			name:      "atomic group",
+			pattern:   `/(?>ab)c`,
+			want:      `(?i)^/(?:ab)c`,
+			wantNotes: []string{"atomic group at offset 1 rewritten as a plain group"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes, err := translatePCRE(tt.pattern)
+			if err != nil {
+				t.Fatalf("translatePCRE(%q) error = %v", tt.pattern, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("translatePCRE(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+
+			if !reflect.DeepEqual(notes, tt.wantNotes) {
+				t.Errorf("translatePCRE(%q) notes = %q, want %q", tt.pattern, notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestTranslatePCREErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{name: "lookahead", pattern: `/(?=a)`, want: "lookahead (?= at offset 1"},
+		{name: "negative lookahead", pattern: `/(?!a)`, want: "negative lookahead (?! at offset 1"},
+		{name: "lookbehind", pattern: `/(?<=a)b`, want: "lookbehind (?<= at offset 1"},
+		{name: "negative lookbehind", pattern: `/(?<!a)b`, want: "negative lookbehind (?<! at offset 1"},
+		{name: "numbered backreference", pattern: `/(a)\1`, want: `backreference \1 at offset 4`},
+		{name: "named backreference", pattern: `/(?<n>a)\k<n>`, want: `backreference \k<n> at offset 8`},
+		{name: "relative backreference", pattern: `/(a)\g{1}`, want: `backreference \g{1} at offset 4`},
+		{name: "python backreference", pattern: `/(?P<n>a)(?P=n)`, want: "backreference (?P=n) at offset 9"},
+		{name: "recursion", pattern: `/(a)(?1)`, want: "recursion (?1) at offset 4"},
+		{name: "unsupported flag", pattern: `/(?x)a`, want: "flag (?x at offset 1"},
+		{name: "verb", pattern: `/(*UTF)a`, want: "verb (*UTF) at offset 1"},
+		{name: "trailing backslash", pattern: `/a\`, want: `a trailing \`},
+		{name: "invalid regex", pattern: `/(a`, want: "missing closing )"},
+		{
+			name:    "every construct is named",
+			pattern: `/(?=a)(b)\1`,
+			want:    `lookahead (?= at offset 1; backreference \1 at offset 9`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := translatePCRE(tt.pattern)
+			if err == nil {
+				t.Fatalf("translatePCRE(%q) = %q, want an error", tt.pattern, got)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("translatePCRE(%q) error = %q, want it to contain %q", tt.pattern, err.Error(), tt.want)
+			}
+		})
+	}
+}
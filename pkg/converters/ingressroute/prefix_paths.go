@@ -0,0 +1,53 @@
+package ingressroute
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const reportPrefixPaths = "prefix-path-semantics"
+
+// strictPrefix reports whether Prefix paths match whole path elements, which
+// is the default unless the loose mode was asked for.
+func strictPrefix(ctx configs.Context) bool {
+	return ctx.Options == nil || !ctx.Options.LoosePrefixPaths
+}
+
+// isElementPrefix reports whether the strict mode changes the matcher of
+// path: a plain Prefix path other than "/". As in buildPathMatch, a path
+// without a pathType is a Prefix path.
+func isElementPrefix(path netv1.HTTPIngressPath, useRegex bool) bool {
+	pathType := netv1.PathTypePrefix
+	if path.PathType != nil {
+		pathType = *path.PathType
+	}
+
+	if useRegex || looksLikeRegex(path.Path) || pathType != netv1.PathTypePrefix {
+		return false
+	}
+
+	return strings.Trim(path.Path, "/") != ""
+}
+
+// elementPrefixMatch returns a matcher with the Kubernetes Prefix semantics:
+// "/foo" and "/foo/" both match "/foo" and "/foo/bar", but not "/foobar".
+func elementPrefixMatch(pth string) string {
+	base := strings.TrimRight(pth, "/")
+	if base == "" {
+		return "PathPrefix(`/`)"
+	}
+
+	return fmt.Sprintf("(Path(`%s`) || PathPrefix(`%s/`))", base, base)
+}
+
+// displayHost returns the host of a rule, "*" for rules without one.
+func displayHost(host string) string {
+	if host == "" {
+		return "*"
+	}
+
+	return host
+}
@@ -0,0 +1,69 @@
+package ingressroute
+
+import (
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// priorityStep separates two path lengths, leaving room for the canary
+	// routes stacked on top of a route and for duplicate paths lowered by
+	// convert.Priorities.
+	priorityStep = 10
+
+	// maxPriorityPathLength caps the path length counted, so the path tiers
+	// never overlap the host tiers.
+	maxPriorityPathLength = 4000
+
+	// priorityExactPath lifts Exact paths above every prefix and regex path
+	// of their host, as an nginx "location =" wins before any other location.
+	priorityExactPath = priorityStep * (maxPriorityPathLength + 1)
+
+	// Host tiers: nginx first picks the server by host, exact server names
+	// before wildcard ones, and only uses the catch-all server for requests
+	// no server name matches.
+	priorityWildcardHost = 2 * priorityExactPath
+	priorityExactHost    = 2 * priorityWildcardHost
+)
+
+// RoutePriority returns the Traefik priority reproducing nginx's location
+// precedence for a path of the rule with the given host. Within a host,
+// Exact paths win, then longer paths before shorter ones, as ingress-nginx
+// orders the locations of a server by path length. The value only depends
+// on host and path, so it is consistent across every Ingress sharing a host.
+func RoutePriority(host string, path netv1.HTTPIngressPath) int {
+	length := len(path.Path)
+	if length == 0 {
+		length = 1 // an empty path is "/"
+	}
+
+	if length > maxPriorityPathLength {
+		length = maxPriorityPathLength
+	}
+
+	priority := hostPriority(host) + priorityStep*length
+
+	if path.PathType != nil && *path.PathType == netv1.PathTypeExact {
+		priority += priorityExactPath
+	}
+
+	return priority
+}
+
+// hostPriority returns the tier of the routes of host.
+func hostPriority(host string) int {
+	switch {
+	case host == "":
+		return 0
+	case isWildcardHost(host):
+		return priorityWildcardHost
+	default:
+		return priorityExactHost
+	}
+}
+
+// ShadowedPriority returns the priority of a route whose host and path are
+// also served by an older Ingress, which nginx prefers. It stays above the
+// next shorter path of the host.
+func ShadowedPriority(priority int) int {
+	return priority - priorityStep/2
+}
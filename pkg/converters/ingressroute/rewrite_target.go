@@ -0,0 +1,190 @@
+package ingressroute
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+/* ---------------- REWRITE ---------------- */
+
+// rewriteTargets builds the ReplacePathRegex middlewares of the
+// "nginx.ingress.kubernetes.io/rewrite-target" annotation. ingress-nginx
+// rewrites with the regex of each location, rewrite "(?i)^path" target, so
+// every path gets its own middleware and the target may use its capture
+// groups. Paths with the same regex share a middleware.
+type rewriteTargets struct {
+	target      string
+	middlewares []*traefik.Middleware
+	byRegex     map[string]string
+	skipped     bool
+}
+
+// newRewriteTargets returns nil when the Ingress has no rewrite-target.
+func newRewriteTargets(ctx configs.Context) *rewriteTargets {
+	target, ok := ctx.Annotations[string(models.RewriteTarget)]
+	if !ok {
+		return nil
+	}
+
+	return &rewriteTargets{
+		target:  strings.TrimSpace(target),
+		byRegex: make(map[string]string),
+	}
+}
+
+// ref returns the reference to the middleware rewriting the requests of path,
+// creating it on first use. It returns false, after recording why, when the
+// rewrite cannot be converted.
+func (r *rewriteTargets) ref(ctx configs.Context, path netv1.HTTPIngressPath, host string) (traefik.MiddlewareRef, bool) {
+	regex, replacement, err := rewriteRegex(path.Path, r.target)
+	if err != nil {
+		msg := fmt.Sprintf("path %q of host %q is not rewritten: %s", path.Path, displayHost(host), err.Error())
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(string(models.RewriteTarget), msg)
+
+		r.skipped = true
+
+		return traefik.MiddlewareRef{}, false
+	}
+
+	name, ok := r.byRegex[regex]
+	if !ok {
+		name = fmt.Sprintf("%s-rewrite-%d", ctx.IngressName, len(r.middlewares)+1)
+		r.byRegex[regex] = name
+
+		r.middlewares = append(r.middlewares, &traefik.Middleware{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: traefik.SchemeGroupVersion.String(),
+				Kind:       "Middleware",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ctx.Namespace,
+			},
+			Spec: traefik.MiddlewareSpec{
+				ReplacePathRegex: &dynamic.ReplacePathRegex{
+					Regex:       regex,
+					Replacement: replacement,
+				},
+			},
+		})
+	}
+
+	return traefik.MiddlewareRef{Name: name}, true
+}
+
+// finish adds the middlewares to the result and reports the annotation.
+func (r *rewriteTargets) finish(ctx configs.Context) {
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, r.middlewares...)
+
+	if len(r.middlewares) != 0 && !r.skipped {
+		ctx.ReportConverted(string(models.RewriteTarget))
+	}
+}
+
+// rewriteRegex returns the ReplacePathRegex regex and replacement rewriting
+// the requests of the location pth to target. nginx replaces the whole URI,
+// while Traefik only replaces the matched text, so the regex is extended to
+// the end of the path.
+func rewriteRegex(pth, target string) (string, string, error) {
+	if pth == "" {
+		pth = "/"
+	}
+
+	translated, _, err := translatePCRE(pth)
+	if err != nil {
+		return "", "", err
+	}
+
+	regex := "(?i)^(?:" + strings.TrimPrefix(translated, "(?i)^") + ").*"
+
+	replacement, err := goReplacement(target)
+	if err != nil {
+		return "", "", err
+	}
+
+	return regex, replacement, nil
+}
+
+// goReplacement converts the nginx capture references $N and ${N} of target
+// to the ${N} form of Go, which does not read the text after the number as
+// part of a group name. Groups the path does not have are empty in both.
+// Other nginx variables have no Traefik equivalent.
+func goReplacement(target string) (string, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(target); {
+		if target[i] != '$' {
+			out.WriteByte(target[i])
+			i++
+
+			continue
+		}
+
+		ref, end := captureReference(target, i+1)
+
+		switch {
+		case ref != "":
+			out.WriteString("${" + ref + "}")
+		case end > i+1:
+			return "", &errors.ConverterError{Message: fmt.Sprintf(
+				"rewrite-target %q uses nginx variable %s, which Traefik cannot substitute", target, target[i:end])}
+		default:
+			out.WriteString("$$")
+		}
+
+		i = end
+	}
+
+	return out.String(), nil
+}
+
+// captureReference parses the reference after the $ at target[start-1]. It
+// returns the group number, if the reference is one, and the offset after
+// the reference. As in nginx, an unbraced capture reference is one digit.
+func captureReference(target string, start int) (string, int) {
+	if start < len(target) && target[start] >= '0' && target[start] <= '9' {
+		return target[start : start+1], start + 1
+	}
+
+	braced := start < len(target) && target[start] == '{'
+
+	begin := start
+	if braced {
+		begin++
+	}
+
+	end := begin
+	for end < len(target) && (target[end] == '_' || isAlphaNum(target[end])) {
+		end++
+	}
+
+	name := target[begin:end]
+	if braced && end < len(target) && target[end] == '}' {
+		end++
+	}
+
+	if name == "" {
+		return "", start
+	}
+
+	if _, err := strconv.Atoi(name); err != nil {
+		return "", end
+	}
+
+	return name, end
+}
+
+func isAlphaNum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,219 @@
+package ingressroute
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/tls"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	traefiktypes "github.com/traefik/traefik/v3/pkg/types"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const reportTLSHosts = "spec.tls"
+
+// hostRoute is a route with the Ingress host it serves.
+type hostRoute struct {
+	host  string
+	route traefik.Route
+}
+
+// tlsGroup holds the routes of the hosts served with the same certificate,
+// which become one IngressRoute.
+type tlsGroup struct {
+	tls    bool
+	secret string
+	hosts  []string
+	routes []traefik.Route
+}
+
+// groupRoutes splits the routes by the spec.tls entry covering their host,
+// in the order the hosts first appear. Hosts that no entry covers are served
+// over plain HTTP, as nginx only serves the certificate of an Ingress to the
+// hosts listed in its TLS section.
+func groupRoutes(ing *netv1.Ingress, routes []hostRoute) []*tlsGroup {
+	groups := make([]*tlsGroup, 0)
+	byKey := make(map[string]*tlsGroup)
+
+	for _, hr := range routes {
+		entry, covered := hostTLS(ing, hr.host)
+
+		key := "http"
+		if covered {
+			key = "tls|" + entry.SecretName
+		}
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &tlsGroup{tls: covered}
+			if covered {
+				group.secret = entry.SecretName
+			}
+
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+
+		if !containsHost(group.hosts, hr.host) {
+			group.hosts = append(group.hosts, hr.host)
+		}
+
+		group.routes = append(group.routes, hr.route)
+	}
+
+	return groups
+}
+
+// hostTLS returns the spec.tls entry whose certificate nginx serves for host.
+// Entries listing the host win over wildcard entries, and entries without
+// hosts apply to every host of the Ingress.
+func hostTLS(ing *netv1.Ingress, host string) (netv1.IngressTLS, bool) {
+	matchers := []func(tlsHost string) bool{
+		func(tlsHost string) bool { return strings.EqualFold(tlsHost, host) },
+		func(tlsHost string) bool { return wildcardCovers(tlsHost, host) },
+	}
+
+	for _, matches := range matchers {
+		for _, entry := range ing.Spec.TLS {
+			for _, tlsHost := range entry.Hosts {
+				if matches(tlsHost) {
+					return entry, true
+				}
+			}
+		}
+	}
+
+	for _, entry := range ing.Spec.TLS {
+		if len(entry.Hosts) == 0 {
+			return entry, true
+		}
+	}
+
+	return netv1.IngressTLS{}, false
+}
+
+// wildcardCovers reports whether a wildcard TLS host such as "*.example.com"
+// covers host, which must have exactly one more label.
+func wildcardCovers(tlsHost, host string) bool {
+	if !isWildcardHost(tlsHost) || host == "" {
+		return false
+	}
+
+	suffix := strings.ToLower(tlsHost[1:])
+	host = strings.ToLower(host)
+
+	return strings.HasSuffix(host, suffix) && !strings.Contains(strings.TrimSuffix(host, suffix), ".")
+}
+
+// groupIngressRoutes builds one IngressRoute per group. The first keeps the
+// usual name so single-certificate Ingresses convert as before.
+func groupIngressRoutes(ctx configs.Context, groups []*tlsGroup) []*traefik.IngressRoute {
+	ing := ctx.Ingress
+	baseName := ing.Name + configs.ConvertedSuffix
+	ingressRoutes := make([]*traefik.IngressRoute, 0, len(groups))
+
+	for i, group := range groups {
+		name := baseName
+		if i > 0 {
+			name = baseName + "-" + groupSuffix(group)
+		}
+
+		// Frontend TLS (spec.tls) promotes to the HTTPS entry point, see
+		// middleware.SSLRedirect for the HTTP side of TLS hosts.
+		entryPoints := []string{ctx.HTTPEntryPoint()}
+		if group.tls {
+			entryPoints = []string{ctx.HTTPSEntryPoint()}
+		}
+
+		ingressRoute := &traefik.IngressRoute{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: traefik.SchemeGroupVersion.String(),
+				Kind:       "IngressRoute",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ing.Namespace,
+			},
+			Spec: traefik.IngressRouteSpec{
+				EntryPoints: entryPoints,
+				Routes:      group.routes,
+			},
+		}
+
+		if group.tls {
+			applyGroupTLS(ingressRoute, group)
+
+			// Apply mTLS TLS Option if present (may extend TLS section).
+			tls.ApplyTLSOption(ingressRoute, ctx)
+		} else if len(ing.Spec.TLS) > 0 {
+			msg := fmt.Sprintf("hosts %s are not covered by spec.tls and are served over HTTP only by IngressRoute %s, "+
+				"nginx also answers HTTPS for them with its default certificate", quoteHosts(group.hosts), name)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(reportTLSHosts, msg)
+		}
+
+		ingressRoutes = append(ingressRoutes, ingressRoute)
+	}
+
+	if len(ingressRoutes) > 1 {
+		names := make([]string, 0, len(ingressRoutes))
+		for _, ingressRoute := range ingressRoutes {
+			names = append(names, ingressRoute.Name)
+		}
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, fmt.Sprintf(
+			"Ingress hosts use different certificates and were split into IngressRoutes %s", strings.Join(names, ", ")))
+	}
+
+	return ingressRoutes
+}
+
+// applyGroupTLS sets the certificate of the group. Wildcard hosts are added
+// to tls.domains so Traefik can match SNI against their HostRegexp rule.
+func applyGroupTLS(ingressRoute *traefik.IngressRoute, group *tlsGroup) {
+	ingressRoute.Spec.TLS = &traefik.TLS{SecretName: group.secret}
+
+	for _, host := range group.hosts {
+		if isWildcardHost(host) {
+			ingressRoute.Spec.TLS.Domains = append(ingressRoute.Spec.TLS.Domains,
+				traefiktypes.Domain{
+					Main: host,
+				},
+			)
+		}
+	}
+}
+
+// groupSuffix names the IngressRoute of a group after its certificate.
+func groupSuffix(group *tlsGroup) string {
+	switch {
+	case !group.tls:
+		return "no-tls"
+	case group.secret == "":
+		return "default-tls"
+	default:
+		return group.secret
+	}
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func quoteHosts(hosts []string) string {
+	quoted := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		quoted = append(quoted, fmt.Sprintf("%q", displayHost(host)))
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -1,40 +1,158 @@
 package middleware
 
 import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 /* ---------------- AUTH URL ---------------- */
 
+const (
+	// defaultAuthSigninRedirectParam is the ingress-nginx default for auth-signin-redirect-param.
+	defaultAuthSigninRedirectParam = "rd"
+
+	// authRequestRedirectHeader is the header nginx sets from auth-request-redirect.
+	authRequestRedirectHeader = "X-Auth-Request-Redirect"
+)
+
+// authHostVariables are the nginx variables that hold the requested host,
+// they are expanded when the Ingress serves a single host.
+var authHostVariables = []string{"$http_host", "$host", "$server_name"}
+
 // HandleAuthURL handles the below annotations.
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/auth-url"
+//   - "nginx.ingress.kubernetes.io/auth-method"
+//   - "nginx.ingress.kubernetes.io/auth-response-headers"
+//   - "nginx.ingress.kubernetes.io/auth-request-redirect"
+//   - "nginx.ingress.kubernetes.io/auth-signin"
+//   - "nginx.ingress.kubernetes.io/auth-signin-redirect-param"
+//   - "nginx.ingress.kubernetes.io/auth-proxy-set-headers"
+//   - "nginx.ingress.kubernetes.io/auth-cache-key"
+//   - "nginx.ingress.kubernetes.io/auth-cache-duration"
+//   - "nginx.ingress.kubernetes.io/auth-keepalive"
+//   - "nginx.ingress.kubernetes.io/auth-snippet"
 func HandleAuthURL(ctx configs.Context) {
 	ctx.Log.Debug("running converter HandleAuthURL")
 
 	const ann = string(models.AuthURL)
 
+	related := []models.Annotation{
+		models.AuthMethod,
+		models.AuthResponseHeaders,
+		models.AuthRequestRedirect,
+		models.AuthSignin,
+		models.AuthSigninRedirectParam,
+		models.AuthProxySetHeaders,
+		models.AuthCacheKey,
+		models.AuthCacheDuration,
+		models.AuthKeepalive,
+		models.AuthSnippet,
+	}
+
+	ignoreRelated := func(msg string) {
+		for _, rel := range related {
+			if _, ok := ctx.Annotations[string(rel)]; ok {
+				ctx.ReportIgnored(string(rel), msg)
+			}
+		}
+	}
+
 	val, ok := ctx.Annotations[ann]
 	if !ok || strings.TrimSpace(val) == "" {
+		ignoreRelated("auth-url is not set")
+
 		return
 	}
 
-	address := strings.TrimSpace(val)
+	address, unresolved := expandAuthHost(ctx, strings.TrimSpace(val))
 
 	// Basic sanity check
 	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
 		msg := "auth-url must be an absolute URL (http:// or https://)"
 		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
 		ctx.ReportSkipped(ann, msg)
+		ignoreRelated("auth-url could not be converted")
 
 		return
 	}
 
+	forwardAuth := &traefik.ForwardAuth{
+		Address:            address,
+		TrustForwardHeader: true,
+	}
+
+	// nginx forwards every request header to the auth service, as does
+	// Traefik when AuthRequestHeaders is left empty.
+	msg := "auth-url converted to Traefik ForwardAuth middleware; the auth request carries " +
+		"X-Forwarded-Method/X-Forwarded-Uri instead of nginx's X-Original-Method/X-Original-URL"
+	if len(unresolved) != 0 {
+		msg = fmt.Sprintf("%s; nginx variables %s are not expanded by Traefik", msg, strings.Join(unresolved, ", "))
+	}
+
+	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+	ctx.ReportWarning(ann, msg)
+
+	annResponseHeaders := string(models.AuthResponseHeaders)
+	if headers, ok := ctx.Annotations[annResponseHeaders]; ok {
+		forwardAuth.AuthResponseHeaders = splitAndTrim(headers)
+
+		ctx.ReportConverted(annResponseHeaders)
+	}
+
+	annMethod := string(models.AuthMethod)
+	if method, ok := ctx.Annotations[annMethod]; ok {
+		if strings.EqualFold(strings.TrimSpace(method), "GET") {
+			ctx.ReportConverted(annMethod)
+		} else {
+			msg := fmt.Sprintf("auth-method %q is not supported, Traefik ForwardAuth always sends GET requests", method)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annMethod, msg)
+		}
+	}
+
+	authRequestRedirect(ctx)
+	authSignin(ctx, address)
+
+	annProxySetHeaders := string(models.AuthProxySetHeaders)
+	if _, ok := ctx.Annotations[annProxySetHeaders]; ok {
+		msg := "auth-proxy-set-headers is not supported, Traefik ForwardAuth cannot add headers to the auth request; " +
+			"set them with a Headers middleware placed before the ForwardAuth middleware"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annProxySetHeaders, msg)
+	}
+
+	for _, cacheAnn := range []models.Annotation{models.AuthCacheKey, models.AuthCacheDuration} {
+		if _, ok := ctx.Annotations[string(cacheAnn)]; ok {
+			ctx.ReportSkipped(string(cacheAnn), "Traefik ForwardAuth does not cache auth responses, every request is sent to the auth service")
+		}
+	}
+
+	annKeepalive := string(models.AuthKeepalive)
+	if _, ok := ctx.Annotations[annKeepalive]; ok {
+		ctx.ReportIgnored(annKeepalive, "Traefik keeps connections to the auth service alive by default")
+	}
+
+	annSnippet := string(models.AuthSnippet)
+	if _, ok := ctx.Annotations[annSnippet]; ok {
+		msg := "auth-snippet is not supported, raw NGINX configuration cannot be converted"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annSnippet, msg)
+	}
+
 	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: traefik.SchemeGroupVersion.String(),
@@ -45,17 +163,241 @@ func HandleAuthURL(ctx configs.Context) {
 			Namespace: ctx.Namespace,
 		},
 		Spec: traefik.MiddlewareSpec{
-			ForwardAuth: &traefik.ForwardAuth{
-				Address:            address,
-				TrustForwardHeader: true,
-			},
+			ForwardAuth: forwardAuth,
 		},
 	})
+}
+
+// authRequestRedirect sets the X-Auth-Request-Redirect header on the request
+// with a Headers middleware. Traefik forwards all request headers to the auth
+// service, so the header reaches it as it does with nginx.
+func authRequestRedirect(ctx configs.Context) {
+	annRequestRedirect := string(models.AuthRequestRedirect)
+
+	val, ok := ctx.Annotations[annRequestRedirect]
+	if !ok || strings.TrimSpace(val) == "" {
+		return
+	}
+
+	if strings.Contains(val, "$") {
+		msg := "auth-request-redirect uses nginx variables, which Traefik cannot expand"
 
-	// Warn about partial compatibility / related annotations
-	ctx.Result.Warnings = append(ctx.Result.Warnings,
-		"auth-url converted to Traefik ForwardAuth middleware; verify headers and auth behavior",
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annRequestRedirect, msg)
+
+		return
+	}
+
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares,
+		newHeadersMiddleware(ctx, "auth-request-redirect", &dynamic.Headers{
+			CustomRequestHeaders: map[string]string{
+				authRequestRedirectHeader: strings.TrimSpace(val),
+			},
+		}),
 	)
 
-	ctx.ReportConverted(ann)
+	ctx.ReportWarning(annRequestRedirect, authRequestRedirectHeader+" is set on the request itself, so the backend receives it too")
+}
+
+// authSignin converts the sign-in redirect nginx returns when the auth
+// service answers 401 into an Errors middleware. The sign-in page is fetched
+// from the in-cluster service behind auth-signin or auth-url, and its
+// response, including the Location header, is returned with status 302.
+func authSignin(ctx configs.Context, authAddress string) {
+	annSignin := string(models.AuthSignin)
+	annParam := string(models.AuthSigninRedirectParam)
+
+	val, ok := ctx.Annotations[annSignin]
+	if !ok || strings.TrimSpace(val) == "" {
+		if _, ok := ctx.Annotations[annParam]; ok {
+			ctx.ReportIgnored(annParam, "auth-signin is not set")
+		}
+
+		return
+	}
+
+	skip := func(msg string) {
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annSignin, msg)
+
+		if _, ok := ctx.Annotations[annParam]; ok {
+			ctx.ReportIgnored(annParam, "auth-signin could not be converted")
+		}
+	}
+
+	signin, err := url.Parse(strings.TrimSpace(val))
+	if err != nil {
+		skip(fmt.Sprintf("invalid auth-signin %q: %v", val, err))
+
+		return
+	}
+
+	service, ok := clusterService(ctx, signin)
+	if !ok {
+		// The sign-in page is usually served by the auth service itself.
+		auth, err := url.Parse(authAddress)
+		if err == nil {
+			service, ok = clusterService(ctx, auth)
+		}
+	}
+
+	if !ok {
+		skip("auth-signin could not be converted, neither auth-signin nor auth-url points to an in-cluster service " +
+			"that an Errors middleware could fetch the sign-in redirect from")
+
+		return
+	}
+
+	param := defaultAuthSigninRedirectParam
+	if custom := strings.TrimSpace(ctx.Annotations[annParam]); custom != "" {
+		param = custom
+
+		ctx.ReportConverted(annParam)
+	}
+
+	query := signinQuery(signin, param)
+
+	msg := fmt.Sprintf("auth-signin converted to an Errors middleware rewriting 401 to 302 with the response of %s%s; "+
+		"the redirect parameter only carries the request path, not the scheme and host",
+		service.Name, query)
+	if service.Namespace != "" {
+		msg += fmt.Sprintf("; the service is in namespace %s, which requires allowCrossNamespace in Traefik", service.Namespace)
+	}
+
+	if strings.Contains(query, "$") {
+		msg += "; nginx variables left in the sign-in URL are not expanded by Traefik"
+	}
+
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "Middleware",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mwName(ctx, "auth-signin"),
+			Namespace: ctx.Namespace,
+		},
+		Spec: traefik.MiddlewareSpec{
+			Errors: &traefik.ErrorPage{
+				Status:         []string{"401"},
+				StatusRewrites: map[string]int{"401": 302},
+				Service:        service,
+				Query:          query,
+			},
+		},
+	})
+
+	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+	ctx.ReportWarning(annSignin, msg)
+}
+
+// signinQuery returns the path and query the sign-in page is fetched with.
+// The nginx request URI variables become Traefik's {url} placeholder, and
+// the redirect parameter is appended when missing, as ingress-nginx does.
+func signinQuery(signin *url.URL, param string) string {
+	query := signin.EscapedPath()
+	if query == "" {
+		query = "/"
+	}
+
+	rawQuery := signin.RawQuery
+	for _, variable := range []string{"$escaped_request_uri", "$request_uri"} {
+		rawQuery = strings.ReplaceAll(rawQuery, variable, "{url}")
+	}
+
+	values, _ := url.ParseQuery(signin.RawQuery)
+	if !values.Has(param) {
+		if rawQuery != "" {
+			rawQuery += "&"
+		}
+
+		rawQuery += param + "={url}"
+	}
+
+	return query + "?" + rawQuery
+}
+
+// clusterService returns the Kubernetes service addressed by u, when its host
+// is an in-cluster service name such as "svc", "svc.ns.svc" or
+// "svc.ns.svc.cluster.local".
+func clusterService(ctx configs.Context, u *url.URL) (traefik.Service, bool) {
+	host := u.Hostname()
+	if host == "" || net.ParseIP(host) != nil || strings.Contains(host, "$") {
+		return traefik.Service{}, false
+	}
+
+	var name, namespace string
+
+	labels := strings.Split(strings.TrimSuffix(host, ".cluster.local"), ".")
+
+	switch {
+	case len(labels) == 1:
+		name = labels[0]
+	case len(labels) == 3 && labels[2] == "svc":
+		name, namespace = labels[0], labels[1]
+	default:
+		return traefik.Service{}, false
+	}
+
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+
+	if p := u.Port(); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil {
+			return traefik.Service{}, false
+		}
+
+		port = parsed
+	}
+
+	service := traefik.Service{
+		LoadBalancerSpec: traefik.LoadBalancerSpec{
+			Name: name,
+			Port: intstr.FromInt32(int32(port)), //nolint:gosec
+		},
+	}
+
+	if namespace != "" && namespace != ctx.Namespace {
+		service.Namespace = namespace
+	}
+
+	return service, true
+}
+
+// expandAuthHost replaces the nginx host variables in address with the host
+// of the Ingress when it serves exactly one. The nginx variables that are
+// left are returned.
+func expandAuthHost(ctx configs.Context, address string) (string, []string) {
+	if !strings.Contains(address, "$") {
+		return address, nil
+	}
+
+	hosts := make(map[string]struct{})
+
+	for _, rule := range ctx.Ingress.Spec.Rules {
+		if rule.Host != "" {
+			hosts[rule.Host] = struct{}{}
+		}
+	}
+
+	if len(hosts) == 1 {
+		for host := range hosts {
+			for _, variable := range authHostVariables {
+				address = strings.ReplaceAll(address, variable, host)
+			}
+		}
+	}
+
+	var unresolved []string
+
+	for _, field := range strings.FieldsFunc(address, func(r rune) bool { return r == '/' || r == '?' || r == '&' || r == '=' }) {
+		if idx := strings.Index(field, "$"); idx >= 0 {
+			unresolved = append(unresolved, field[idx:])
+		}
+	}
+
+	return address, unresolved
 }
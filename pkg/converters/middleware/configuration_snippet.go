@@ -3,13 +3,14 @@ package middleware
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
-	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/nginx"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -56,11 +57,36 @@ var unsupported = map[string]unsupportedDirective{
 	},
 }
 
+// snippetHeader is a header set by an add_header, more_set_headers or
+// proxy_set_header directive.
+type snippetHeader struct {
+	Name  string
+	Value string
+}
+
+// snippetConversion collects what the directives of a configuration-snippet
+// convert to. Every warning starts with the line of the directive it is
+// about.
+type snippetConversion struct {
+	reqHeaders  map[string]string
+	respHeaders map[string]string
+	warnings    []string
+	converted   bool
+}
+
+func (s *snippetConversion) warn(directive *nginx.Directive, format string, args ...any) {
+	s.warnings = append(s.warnings, fmt.Sprintf("line %d: ", directive.Line)+fmt.Sprintf(format, args...))
+}
+
 /* ---------------- CONFIGURATION SNIPPET ---------------- */
 
 // ConfigurationSnippets handles the below annotations.
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/configuration-snippet"
+//
+// The snippet is parsed as NGINX configuration. Header directives are
+// converted to a Headers middleware, and conditional CORS logic to Traefik
+// CORS headers; every other directive is reported with its line.
 func ConfigurationSnippets(ctx configs.Context) error {
 	ctx.Log.Debug("running converter ConfigurationSnippet")
 
@@ -71,185 +97,379 @@ func ConfigurationSnippets(ctx configs.Context) error {
 		return nil
 	}
 
-	lines := splitLines(snippet)
-	if len(lines) == 0 {
+	directives, err := nginx.Parse(snippet)
+	if err != nil {
+		msg := "configuration-snippet is not valid NGINX configuration and was skipped: " + err.Error()
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(ann, msg)
+
 		return nil
 	}
 
-	// 🔒 Conditional CORS handling
-	if isConditionalCORSSnippet(lines) {
-		cfg, err := parseConditionalCORSSnippet(lines)
-		if err != nil {
-			ctx.Result.Warnings = append(ctx.Result.Warnings,
-				"failed to parse conditional CORS snippet; skipped",
-			)
+	if len(directives) == 0 {
+		return nil
+	}
 
+	conversion := &snippetConversion{
+		reqHeaders:  make(map[string]string),
+		respHeaders: make(map[string]string),
+	}
+
+	// 🔒 Conditional CORS handling
+	cors := conditionalCORS(directives, conversion)
+	if cors != nil {
+		if err = emitCORS(ctx, cors); err != nil {
 			return err
 		}
+	}
 
-		emitCORSMiddleware(ctx, cfg)
-
-		if cr := parseConditionalReturn(lines); cr != nil {
-			if err = emitConditionalReturnPlugin(ctx, cr); err != nil {
-				return err
-			}
+	for _, directive := range directives {
+		if cors != nil && cors.consumes(directive) {
+			continue
 		}
 
-		return nil
+		convertSnippetDirective(directive, conversion)
 	}
 
-	convertGenericSnippet(ctx, lines)
+	if len(conversion.reqHeaders) != 0 || len(conversion.respHeaders) != 0 {
+		ctx.Result.Middlewares = append(
+			ctx.Result.Middlewares,
+			newHeadersMiddleware(ctx, "configuration-snippet", &dynamic.Headers{
+				CustomRequestHeaders:  conversion.reqHeaders,
+				CustomResponseHeaders: conversion.respHeaders,
+			}),
+		)
+	}
 
-	ctx.ReportConverted(ann)
+	ctx.Result.Warnings = append(ctx.Result.Warnings, conversion.warnings...)
+
+	switch {
+	case len(conversion.warnings) == 0:
+		ctx.ReportConverted(ann)
+	case conversion.converted:
+		for _, msg := range conversion.warnings {
+			ctx.ReportWarning(ann, msg)
+		}
+	default:
+		for _, msg := range conversion.warnings {
+			ctx.ReportSkipped(ann, msg)
+		}
+	}
 
 	return nil
 }
 
 /* ---------------- Generic snippet handling ---------------- */
 
-func convertGenericSnippet(ctx configs.Context, lines []string) {
-	const (
-		reqHeadersCount  = 4
-		respHeadersCount = 8
-		warningsCount    = 4
-	)
+func convertSnippetDirective(directive *nginx.Directive, conversion *snippetConversion) {
+	if directive.Block != nil {
+		if directive.Name == "if" {
+			conversion.warn(directive, "NGINX if blocks are not converted, the block was ignored")
+		} else {
+			conversion.warn(directive, "unsupported block %q in configuration-snippet was ignored", directive.Name)
+		}
 
-	reqHeaders := make(map[string]string, reqHeadersCount)
-	respHeaders := make(map[string]string, respHeadersCount)
-	warnings := make([]string, 0, warningsCount)
+		return
+	}
 
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		if line == "" {
-			continue
+	switch directive.Name {
+	case "add_header", "more_set_headers":
+		headers, ok := parseResponseHeaders(directive, conversion)
+		if !ok {
+			return
 		}
 
-		lower := strings.ToLower(line)
+		for _, header := range headers {
+			conversion.respHeaders[header.Name] = header.Value
+			conversion.converted = true
 
-		switch directive(lower) {
-		case "add_header", "more_set_headers":
-			if k, v, ok := parseResponseHeader(line); ok {
-				respHeaders[k] = v
-			} else {
-				warnings = append(warnings,
-					"failed to parse header directive: "+line,
-				)
-			}
+			warnVariables(directive, header, conversion)
+		}
 
-		case "proxy_set_header":
-			key, val := parseProxySetHeader(line)
-			if key != "" {
-				reqHeaders[key] = val
-			}
+	case "proxy_set_header":
+		if len(directive.Args) != 2 {
+			conversion.warn(directive, "proxy_set_header expects a header name and a value, the directive was ignored")
 
-			if strings.Contains(val, "$") {
-				warnings = append(warnings,
-					"proxy_set_header uses NGINX variables which are not evaluated by Traefik",
-				)
-			}
+			return
+		}
 
-		case "gzip", "gzip_comp_level", "gzip_types", "proxy_buffer_size", "proxy_cache":
-			if u, ok := unsupported[directive(lower)]; ok {
-				warnUnsupported(&warnings, u)
-			}
+		header := snippetHeader{Name: directive.Args[0], Value: directive.Args[1]}
 
-		default:
-			warnings = append(warnings,
-				"unsupported directive in configuration-snippet was ignored: "+line,
-			)
+		conversion.reqHeaders[header.Name] = header.Value
+		conversion.converted = true
+
+		warnVariables(directive, header, conversion)
+
+	default:
+		if u, ok := unsupported[directive.Name]; ok {
+			warnUnsupported(directive, u, conversion)
+
+			return
 		}
+
+		conversion.warn(directive, "unsupported directive %q in configuration-snippet was ignored", directive.Name)
 	}
+}
 
-	ctx.Result.Warnings = append(ctx.Result.Warnings, warnings...)
+// parseResponseHeaders returns the headers set by an add_header or
+// more_set_headers directive. An empty more_set_headers value clears the
+// header, as an empty Traefik custom header does.
+func parseResponseHeaders(directive *nginx.Directive, conversion *snippetConversion) ([]snippetHeader, bool) {
+	args := directive.Args
 
-	if len(reqHeaders) == 0 && len(respHeaders) == 0 {
-		return
+	if directive.Name == "add_header" {
+		if len(args) == 3 && args[2] == "always" {
+			args = args[:2]
+		}
+
+		if len(args) != 2 {
+			conversion.warn(directive, "add_header expects a header name, a value and an optional always, the directive was ignored")
+
+			return nil, false
+		}
+
+		return []snippetHeader{{Name: args[0], Value: args[1]}}, true
 	}
 
-	ctx.Result.Middlewares = append(
-		ctx.Result.Middlewares,
-		newHeadersMiddleware(ctx, "configuration-snippet", &dynamic.Headers{
-			CustomRequestHeaders:  reqHeaders,
-			CustomResponseHeaders: respHeaders,
-		}),
-	)
+	headers := make([]snippetHeader, 0, len(args))
+
+	for _, arg := range args {
+		if arg == "-s" || arg == "-t" {
+			conversion.warn(directive, "more_set_headers %s only sets the headers of some responses, "+
+				"which Traefik cannot restrict; the directive was ignored", arg)
+
+			return nil, false
+		}
+
+		name, value, ok := strings.Cut(arg, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			conversion.warn(directive, "more_set_headers expects \"Name: value\" parameters, %q was ignored", arg)
+
+			continue
+		}
+
+		headers = append(headers, snippetHeader{Name: name, Value: strings.TrimSpace(value)})
+	}
+
+	return headers, len(headers) != 0
+}
+
+func warnVariables(directive *nginx.Directive, header snippetHeader, conversion *snippetConversion) {
+	if strings.Contains(header.Value, "$") {
+		conversion.warn(directive, "%s %q uses NGINX variables which are not evaluated by Traefik",
+			directive.Name, header.Name)
+	}
 }
 
 /* ---------------- CORS handling ---------------- */
 
+// corsSnippet is the conditional CORS logic of a configuration-snippet.
+type corsSnippet struct {
+	config    *corsConfig
+	preflight *conditionalReturnConfig
+	origin    *nginx.Directive
+	consumed  map[*nginx.Directive]struct{}
+}
+
+func (c *corsSnippet) consumes(directive *nginx.Directive) bool {
+	_, ok := c.consumed[directive]
+
+	return ok
+}
+
 // NOTE:
 // NGINX `if` directives are never converted,
 // except when they implement pure CORS logic.
 // In that case, Traefik's CORS middleware provides equivalent behavior.
-func isConditionalCORSSnippet(lines []string) bool {
-	var hasOriginIf, hasMethods bool
+//
+// conditionalCORS returns nil unless the snippet matches the origin against
+// a regex in an if block and sets Access-Control-Allow-Methods, without
+// rewriting, proxying or setting variables anywhere.
+func conditionalCORS(directives []*nginx.Directive, conversion *snippetConversion) *corsSnippet {
+	var hasMethods, unsafe bool
+
+	nginx.Walk(directives, func(directive *nginx.Directive) {
+		switch {
+		case directive.Name == "rewrite",
+			directive.Name == "proxy_pass",
+			directive.Name == "set",
+			strings.HasPrefix(directive.Name, "fastcgi"),
+			strings.Contains(directive.Name, "lua"):
+			unsafe = true
+		case isHeaderDirective(directive):
+			for _, header := range quietResponseHeaders(directive) {
+				if strings.EqualFold(header.Name, "access-control-allow-methods") {
+					hasMethods = true
+				}
+			}
+		}
+	})
+
+	if unsafe || !hasMethods {
+		return nil
+	}
+
+	cors := &corsSnippet{consumed: make(map[*nginx.Directive]struct{})}
 
-	for _, raw := range lines {
-		line := strings.ToLower(raw)
+	var preflight *nginx.Directive
 
-		if strings.Contains(line, "if ($http_origin") {
-			hasOriginIf = true
+	for _, directive := range directives {
+		if directive.Name != "if" || directive.Block == nil {
+			continue
 		}
 
-		if strings.Contains(line, "access-control-allow-methods") {
-			hasMethods = true
+		condition, err := nginx.IfCondition(directive)
+		if err != nil {
+			continue
 		}
 
-		if strings.Contains(line, "rewrite") ||
-			strings.Contains(line, "proxy_pass") ||
-			strings.Contains(line, "fastcgi") ||
-			strings.Contains(line, "lua_") ||
-			strings.Contains(line, "set ") {
-			return false
+		switch {
+		case cors.origin == nil && len(condition) == 3 && condition[0] == "$http_origin" &&
+			(condition[1] == "~" || condition[1] == "~*"):
+			regex := condition[2]
+			if condition[1] == "~*" {
+				regex = "(?i)" + regex
+			}
+
+			if _, err = regexp.Compile(regex); err != nil {
+				conversion.warn(directive, "origin regex %q is not supported by Traefik, the CORS logic was not converted: %s",
+					condition[2], err.Error())
+
+				return nil
+			}
+
+			cors.origin = directive
+			cors.config = &corsConfig{OriginRegex: regex}
+		case preflight == nil && len(condition) == 3 && condition[0] == "$request_method" &&
+			condition[1] == "=" && condition[2] == "OPTIONS":
+			preflight = directive
+		default:
+			continue
 		}
+
+		cors.consumed[directive] = struct{}{}
 	}
 
-	return hasOriginIf && hasMethods
-}
+	if cors.origin == nil {
+		return nil
+	}
 
-func parseConditionalCORSSnippet(lines []string) (*corsConfig, error) {
-	cfg := &corsConfig{}
+	for _, inner := range cors.origin.Block {
+		if !isAccessControlHeader(inner) {
+			conversion.warn(inner, "directive %q of the $http_origin condition was ignored", inner.Name)
+		}
+	}
 
-	origin, ok := extractOriginRegex(lines)
-	if !ok {
-		return nil, &errors.ConverterError{Message: "no origin regex found"}
+	if preflight != nil {
+		cors.preflight = parseConditionalReturn(preflight, conversion)
 	}
 
-	cfg.OriginRegex = origin
+	parseConditionalCORSSnippet(directives, cors)
 
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		lower := strings.ToLower(line)
+	conversion.converted = true
 
-		switch {
-		case strings.Contains(lower, "access-control-allow-headers"):
-			cfg.AllowHeaders = splitCSV(extractQuotedHeaderValue(line))
+	return cors
+}
 
-		case strings.Contains(lower, "access-control-allow-methods"):
-			cfg.AllowMethods = splitCSV(extractQuotedHeaderValue(line))
+// parseConditionalCORSSnippet reads the Access-Control headers set anywhere
+// in the snippet. The top-level ones are consumed by the CORS middleware.
+func parseConditionalCORSSnippet(directives []*nginx.Directive, cors *corsSnippet) {
+	cfg := cors.config
 
-		case strings.Contains(lower, "access-control-allow-credentials"):
-			v := strings.ToLower(extractQuotedHeaderValue(line))
-			if v == "true" || v == "false" {
-				b := v == "true"
-				cfg.AllowCreds = &b
-			}
+	nginx.Walk(directives, func(directive *nginx.Directive) {
+		if !isHeaderDirective(directive) {
+			return
+		}
+
+		for _, header := range quietResponseHeaders(directive) {
+			switch strings.ToLower(header.Name) {
+			case "access-control-allow-headers":
+				cfg.AllowHeaders = splitCSV(header.Value)
+
+			case "access-control-allow-methods":
+				cfg.AllowMethods = splitCSV(header.Value)
+
+			case "access-control-allow-credentials":
+				v := strings.ToLower(header.Value)
+				if v == "true" || v == "false" {
+					b := v == "true"
+					cfg.AllowCreds = &b
+				}
 
-		case strings.Contains(lower, "access-control-max-age"):
-			if age := extractInt(line); age > 0 {
-				cfg.MaxAge = age
+			case "access-control-max-age":
+				if age, err := strconv.ParseInt(header.Value, 10, 64); err == nil && age > 0 {
+					cfg.MaxAge = age
+				}
 			}
 		}
-	}
+
+		if isAccessControlHeader(directive) {
+			cors.consumed[directive] = struct{}{}
+		}
+	})
 
 	if len(cfg.AllowMethods) == 0 {
 		cfg.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	}
+}
+
+// parseConditionalReturn reads the if ($request_method = OPTIONS) block
+// answering the preflight requests. It returns nil unless the block returns
+// a status code.
+func parseConditionalReturn(directive *nginx.Directive, conversion *snippetConversion) *conditionalReturnConfig {
+	var status int
 
-	return cfg, nil
+	headers := make(map[string]any)
+
+	for _, inner := range directive.Block {
+		switch {
+		case inner.Name == "return" && len(inner.Args) != 0 && inner.Block == nil:
+			if code, err := strconv.Atoi(inner.Args[0]); err == nil {
+				status = code
+			}
+
+		case isHeaderDirective(inner):
+			for _, header := range quietResponseHeaders(inner) {
+				// Special-case list headers
+				switch strings.ToLower(header.Name) {
+				case "access-control-allow-headers", "access-control-allow-methods":
+					if list := splitCSV(header.Value); len(list) > 0 {
+						headers[header.Name] = list
+					} else {
+						headers[header.Name] = header.Value
+					}
+				default:
+					// Best-effort: let CORS middleware handle dynamic origin
+					if strings.Contains(header.Value, "$http_origin") {
+						headers[header.Name] = "*"
+					} else {
+						headers[header.Name] = header.Value
+					}
+				}
+			}
+
+		default:
+			conversion.warn(inner, "directive %q of the OPTIONS preflight block was ignored", inner.Name)
+		}
+	}
+
+	if status == 0 {
+		conversion.warn(directive, "the OPTIONS preflight block does not return a status code and was ignored")
+
+		return nil
+	}
+
+	return &conditionalReturnConfig{
+		Method:     "OPTIONS",
+		StatusCode: status,
+		Headers:    headers,
+	}
 }
 
-func emitCORSMiddleware(ctx configs.Context, cfg *corsConfig) {
+func emitCORS(ctx configs.Context, cors *corsSnippet) error {
+	cfg := cors.config
+
 	headers := &dynamic.Headers{
 		AccessControlAllowMethods: cfg.AllowMethods,
 		AccessControlAllowHeaders: cfg.AllowHeaders,
@@ -268,88 +488,23 @@ func emitCORSMiddleware(ctx configs.Context, cfg *corsConfig) {
 		newHeadersMiddleware(ctx, "cors", headers),
 	)
 
-	if len(cfg.AllowHeaders) == 0 || len(cfg.AllowMethods) == 0 {
+	prefix := fmt.Sprintf("line %d: ", cors.origin.Line)
+
+	if len(cfg.AllowHeaders) == 0 {
 		ctx.Result.Warnings = append(ctx.Result.Warnings,
-			"conditional CORS snippet was partially parsed; verify generated middleware",
+			prefix+"conditional CORS snippet was partially parsed; verify generated middleware",
 		)
 	}
 
 	ctx.Result.Warnings = append(ctx.Result.Warnings,
-		"conditional NGINX CORS logic was converted to Traefik CORS middleware",
-	)
-}
-
-func parseConditionalReturn(lines []string) *conditionalReturnConfig {
-	var (
-		inIf   bool
-		method string
-		status int
+		prefix+"conditional NGINX CORS logic was converted to Traefik CORS middleware",
 	)
 
-	headers := make(map[string]any)
-
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		lower := strings.ToLower(line)
-
-		// Detect: if ($request_method = 'OPTIONS') {
-		if strings.HasPrefix(lower, "if") && strings.Contains(lower, "$request_method") {
-			if strings.Contains(lower, "options") {
-				method = "OPTIONS"
-				inIf = true
-
-				continue
-			}
-		}
-
-		if inIf {
-			// Detect return 204;
-			if strings.HasPrefix(lower, "return") {
-				fields := strings.Fields(lower)
-				if len(fields) >= 2 {
-					if code, err := strconv.Atoi(strings.TrimSuffix(fields[1], ";")); err == nil {
-						status = code
-					}
-				}
-
-				continue
-			}
-
-			//nolint:varnamelen
-			// Parse headers properly
-			if k, v, ok := parseAddHeaderNormalized(line); ok {
-				// Special-case list headers
-				switch strings.ToLower(k) {
-				case "access-control-allow-headers", "access-control-allow-methods":
-					list := splitCSV(v)
-					if len(list) > 0 {
-						headers[k] = list
-					} else {
-						headers[k] = v
-					}
-				default:
-					headers[k] = v
-				}
-
-				continue
-			}
-
-			// End of block
-			if strings.HasPrefix(line, "}") {
-				inIf = false
-			}
-		}
-	}
-
-	if method != "" && status > 0 {
-		return &conditionalReturnConfig{
-			Method:     method,
-			StatusCode: status,
-			Headers:    headers,
-		}
+	if cors.preflight == nil {
+		return nil
 	}
 
-	return nil
+	return emitConditionalReturnPlugin(ctx, cors.preflight)
 }
 
 func emitConditionalReturnPlugin(ctx configs.Context, cfg *conditionalReturnConfig) error {
@@ -387,118 +542,45 @@ func emitConditionalReturnPlugin(ctx configs.Context, cfg *conditionalReturnConf
 	return nil
 }
 
-func parseAddHeaderNormalized(line string) (string, string, bool) {
-	// Trim trailing ;
-	line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
-
-	lower := strings.ToLower(line)
-	if !strings.HasPrefix(lower, "add_header") && !strings.HasPrefix(lower, "more_set_headers") {
-		return "", "", false
-	}
-
-	// Remove directive name
-	fields := strings.Fields(line)
-	if len(fields) < 3 {
-		return "", "", false
-	}
-
-	rest := strings.TrimSpace(line[len(fields[0]):])
-
-	// Remove "always" if present
-	rest = strings.TrimSpace(strings.TrimSuffix(rest, "always"))
-
-	// Now rest should look like:
-	// "Header-Name" "Header Value"
-	// 'Header-Name' 600
-	// Header-Name value
-
-	var key, val string
-
-	// Try quoted key
-	if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, `'`) {
-		quote := rest[0:1]
-
-		end := strings.Index(rest[1:], quote)
-		if end == -1 {
-			return "", "", false
-		}
-
-		end++
-
-		key = rest[1:end]
-		after := strings.TrimSpace(rest[end+1:])
-
-		// Value may be quoted or unquoted
-		if strings.HasPrefix(after, `"`) || strings.HasPrefix(after, `'`) {
-			q := after[0:1]
-
-			e := strings.Index(after[1:], q)
-			if e == -1 {
-				return "", "", false
-			}
-
-			val = after[1 : 1+e]
-		} else {
-			// Unquoted value (e.g. 600)
-			val = strings.TrimSpace(after)
-		}
-	} else {
-		// No quoted key, fallback to fields
-		parts := strings.Fields(rest)
-		if len(parts) < 2 {
-			return "", "", false
-		}
-
-		key = strings.Trim(parts[0], `"'`)
-		val = strings.Trim(strings.Join(parts[1:], " "), `"'`)
-	}
-
-	key = strings.TrimSpace(key)
-	val = strings.TrimSpace(val)
-
-	if key == "" || val == "" {
-		return "", "", false
-	}
-
-	// Handle $http_origin (cannot be evaluated by Traefik)
-	if strings.Contains(val, "$http_origin") {
-		// Best-effort: let CORS middleware handle dynamic origin
-		val = "*"
-	}
+/* ---------------- Helpers ---------------- */
 
-	return key, val, true
+func isHeaderDirective(directive *nginx.Directive) bool {
+	return directive.Block == nil && (directive.Name == "add_header" || directive.Name == "more_set_headers")
 }
 
-/* ---------------- Helpers ---------------- */
+// isAccessControlHeader reports whether a header directive only sets
+// Access-Control headers, which the CORS middleware takes over.
+func isAccessControlHeader(directive *nginx.Directive) bool {
+	if !isHeaderDirective(directive) {
+		return false
+	}
 
-func splitLines(s string) []string {
-	out := make([]string, 0)
+	headers := quietResponseHeaders(directive)
 
-	for _, l := range strings.Split(s, "\n") {
-		if t := strings.TrimSpace(l); t != "" {
-			out = append(out, t)
+	for _, header := range headers {
+		if !strings.HasPrefix(strings.ToLower(header.Name), "access-control-") {
+			return false
 		}
 	}
 
-	return out
+	return len(headers) != 0
 }
 
-func directive(line string) string {
-	fields := strings.Fields(line)
-	if len(fields) == 0 {
-		return ""
-	}
+// quietResponseHeaders returns the headers of a header directive, leaving
+// the invalid ones to be reported where the directive is converted.
+func quietResponseHeaders(directive *nginx.Directive) []snippetHeader {
+	headers, _ := parseResponseHeaders(directive, &snippetConversion{})
 
-	return fields[0]
+	return headers
 }
 
-func warnUnsupported(warnings *[]string, d unsupportedDirective) {
+func warnUnsupported(directive *nginx.Directive, d unsupportedDirective, conversion *snippetConversion) {
 	msg := d.Message
 	if d.Enterprise {
 		msg += ". Traefik Enterprise provides an alternative, but it cannot be auto-converted."
 	}
 
-	*warnings = append(*warnings, msg)
+	conversion.warn(directive, "%s", msg)
 }
 
 func newHeadersMiddleware(
@@ -521,115 +603,6 @@ func newHeadersMiddleware(
 	}
 }
 
-/* ---------------- Parsing helpers ---------------- */
-
-func parseProxySetHeader(line string) (string, string) {
-	line = strings.TrimSuffix(line, ";")
-	parts := strings.Fields(line)
-
-	const proxySetHeaderCount = 3
-
-	if len(parts) < proxySetHeaderCount {
-		return "", ""
-	}
-
-	return strings.Trim(parts[1], `"`), strings.Join(parts[2:], " ")
-}
-
-func parseResponseHeader(line string) (string, string, bool) {
-	line = strings.TrimSuffix(strings.TrimSpace(line), ";")
-
-	if strings.HasPrefix(line, "more_set_headers") {
-		start := strings.Index(line, `"`)
-		end := strings.LastIndex(line, `"`)
-
-		if start == -1 || end <= start {
-			return "", "", false
-		}
-
-		const moreSetHeadersCount = 2
-
-		kv := strings.SplitN(line[start+1:end], ":", moreSetHeadersCount)
-		if len(kv) != moreSetHeadersCount {
-			return "", "", false
-		}
-
-		return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true
-	}
-
-	const addHeaderCount = 3
-
-	if strings.HasPrefix(line, "add_header") {
-		fields := strings.Fields(line)
-		if len(fields) < addHeaderCount {
-			return "", "", false
-		}
-
-		// Only strip when there are 4+ fields (directive + name + value + always);
-		const alwaysMinFields = 4
-
-		if len(fields) >= alwaysMinFields && strings.EqualFold(fields[len(fields)-1], "always") {
-			fields = fields[:len(fields)-1]
-		}
-
-		if len(fields) < addHeaderCount {
-			return "", "", false
-		}
-
-		return strings.Trim(fields[1], `"`),
-			strings.Trim(strings.Join(fields[2:], " "), `"`),
-			true
-	}
-
-	return "", "", false
-}
-
-var originIfRe = regexp.MustCompile(
-	`\$http_origin\s+~\*\s+\((.+?)\)\s*\)`,
-)
-
-func extractOriginRegex(lines []string) (string, bool) {
-	const originRegexCount = 2
-
-	for _, l := range lines {
-		if m := originIfRe.FindStringSubmatch(l); len(m) == originRegexCount {
-			return m[1], true
-		}
-	}
-
-	return "", false
-}
-
-func extractQuotedHeaderValue(line string) string {
-	values := make([]string, 0)
-
-	for _, quote := range []string{`"`, `'`} {
-		tmp := line
-
-		for {
-			start := strings.Index(tmp, quote)
-			if start == -1 {
-				break
-			}
-
-			end := strings.Index(tmp[start+1:], quote)
-			if end == -1 {
-				break
-			}
-
-			end = start + 1 + end
-			values = append(values, tmp[start+1:end])
-			tmp = tmp[end+1:]
-		}
-	}
-
-	if len(values) == 0 {
-		return ""
-	}
-
-	return values[len(values)-1]
-}
-
 func splitCSV(v string) []string {
 	out := make([]string, 0)
 
@@ -641,18 +614,3 @@ func splitCSV(v string) []string {
 
 	return out
 }
-
-func extractInt(line string) int64 {
-	fields := strings.Fields(line)
-	if len(fields) == 0 {
-		return 0
-	}
-
-	newValue, _ := strconv.ParseInt(
-		strings.TrimSuffix(fields[len(fields)-1], ";"),
-		10,
-		64,
-	)
-
-	return newValue
-}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// defaultBackendPort is assumed for the default-backend service, whose
+	// port the annotation does not name.
+	defaultBackendPort = 80
+
+	// customErrorsQuery sends the error page request to "/" as nginx does,
+	// with the status Traefik cannot pass in the X-Code header.
+	customErrorsQuery = "/?code={status}"
+)
+
+/* ---------------- CUSTOM HTTP ERRORS ---------------- */
+
+// CustomHTTPErrors handles the below annotations.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/custom-http-errors"
+//   - "nginx.ingress.kubernetes.io/default-backend"
+//
+// Without custom-http-errors, nginx only uses the default backend when the
+// Ingress services have no endpoints, which Traefik answers with a 503.
+func CustomHTTPErrors(ctx configs.Context) {
+	ctx.Log.Debug("running converter CustomHTTPErrors")
+
+	annErrors := string(models.CustomHTTPErrors)
+	annBackend := string(models.DefaultBackend)
+
+	codes, okErrors := ctx.Annotations[annErrors]
+	backend := strings.TrimSpace(ctx.Annotations[annBackend])
+
+	if !okErrors && backend == "" {
+		return
+	}
+
+	if backend == "" {
+		msg := "custom-http-errors without default-backend uses the controller default backend, " +
+			"which is unknown to the converter; set default-backend or add an Errors middleware manually"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annErrors, msg)
+
+		return
+	}
+
+	status := []string{"503"}
+
+	if okErrors {
+		parsed, err := parseStatusCodes(codes)
+		if err != nil {
+			ctx.Result.Warnings = append(ctx.Result.Warnings, err.Error())
+			ctx.ReportSkipped(annErrors, err.Error())
+			ctx.ReportSkipped(annBackend, err.Error())
+
+			return
+		}
+
+		status = parsed
+	}
+
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "Middleware",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mwName(ctx, "custom-http-errors"),
+			Namespace: ctx.Namespace,
+		},
+		Spec: traefik.MiddlewareSpec{
+			Errors: &traefik.ErrorPage{
+				Status: status,
+				Service: traefik.Service{
+					LoadBalancerSpec: traefik.LoadBalancerSpec{
+						Name: backend,
+						Port: intstr.FromInt32(defaultBackendPort),
+					},
+				},
+				Query: customErrorsQuery,
+			},
+		},
+	})
+
+	if okErrors {
+		msg := fmt.Sprintf("custom-http-errors converted to an Errors middleware requesting %s from %s; "+
+			"nginx sends the status in X-Code and the request details in X-Original-URI, X-Namespace, "+
+			"X-Ingress-Name, X-Service-Name, X-Service-Port and X-Request-ID, which Traefik does not set",
+			customErrorsQuery, backend)
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(annErrors, msg)
+	}
+
+	msg := fmt.Sprintf("default-backend %s is assumed to listen on port %d, verify the Errors middleware service port",
+		backend, defaultBackendPort)
+	if !okErrors {
+		msg = "default-backend converted to an Errors middleware for 503 responses, which also catches " +
+			"the 503s of the backends themselves; " + msg
+	}
+
+	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+	ctx.ReportWarning(annBackend, msg)
+}
+
+// parseStatusCodes validates the comma-separated status codes of
+// custom-http-errors.
+func parseStatusCodes(codes string) ([]string, error) {
+	status := make([]string, 0)
+
+	for _, code := range splitAndTrim(codes) {
+		parsed, err := strconv.Atoi(code)
+		if err != nil || parsed < 300 || parsed > 599 {
+			return nil, &errors.ConverterError{Message: fmt.Sprintf("invalid status code %q in custom-http-errors", code)}
+		}
+
+		status = append(status, code)
+	}
+
+	if len(status) == 0 {
+		return nil, &errors.ConverterError{Message: fmt.Sprintf("custom-http-errors %q lists no status codes", codes)}
+	}
+
+	return status, nil
+}
@@ -78,15 +78,11 @@ via entryPoints.<name>.http.maxHeaderBytes in static configuration`
 		ctx.ReportWarning(string(models.GrpcBackend), warningMessage)
 	}
 
-	// HSTS annotations: these are handled natively by the nginx ingress
-	// controller but in Traefik HSTS is configured via a headers middleware.
-	// If the Ingress also had a configuration-snippet with add_header
-	// Strict-Transport-Security, that header middleware was already generated.
-	for _, hstsAnn := range []models.Annotation{models.HSTS, models.HSTSIncludeSubdomains, models.HSTSMaxAge, models.HSTSPreload} {
-		if _, ok := ctx.Annotations[string(hstsAnn)]; ok {
-			ctx.ReportIgnored(string(hstsAnn),
-				"HSTS annotations are not supported per-Ingress in Traefik; use a headers middleware or Traefik static configuration instead")
-		}
+	// enable-global-auth only opts out of the controller ConfigMap's
+	// global-auth-url, which is resolved when the defaults are applied.
+	if _, ok := ctx.Annotations[string(models.EnableGlobalAuth)]; ok && ctx.ControllerConfig == nil {
+		ctx.ReportIgnored(string(models.EnableGlobalAuth),
+			"enable-global-auth has no effect without a controller ConfigMap (--controller-configmap) setting global-auth-url")
 	}
 
 	// from-to-www-redirect — requires a Traefik RedirectRegex middleware that
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultHSTSMaxAge is the ingress-nginx default for hsts-max-age (one year).
+const defaultHSTSMaxAge = 31536000
+
+/* ---------------- HSTS ---------------- */
+
+// HSTS handles the below annotations, usually inherited from the controller
+// ConfigMap. nginx only sends the header on TLS servers, so it is converted
+// to a Headers middleware only when the Ingress has spec.tls.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/hsts"
+//   - "nginx.ingress.kubernetes.io/hsts-max-age"
+//   - "nginx.ingress.kubernetes.io/hsts-include-subdomains"
+//   - "nginx.ingress.kubernetes.io/hsts-preload"
+func HSTS(ctx configs.Context) error {
+	ctx.Log.Debug("running converter HSTS")
+
+	annHSTS := string(models.HSTS)
+	related := []string{
+		string(models.HSTSMaxAge),
+		string(models.HSTSIncludeSubdomains),
+		string(models.HSTSPreload),
+	}
+
+	reportAll := func(report func(name, msg string), msg string) {
+		for _, ann := range append([]string{annHSTS}, related...) {
+			if _, ok := ctx.Annotations[ann]; ok {
+				report(ann, msg)
+			}
+		}
+	}
+
+	enabled, ok := ctx.Annotations[annHSTS]
+	if !ok {
+		reportAll(ctx.ReportIgnored, "hsts is not enabled")
+
+		return nil
+	}
+
+	if strings.TrimSpace(enabled) != "true" {
+		reportAll(ctx.ReportIgnored, fmt.Sprintf("%s is not set to true", annHSTS))
+
+		return nil
+	}
+
+	if ctx.Ingress == nil || len(ctx.Ingress.Spec.TLS) == 0 {
+		reportAll(ctx.ReportIgnored, "nginx only sends the HSTS header for Ingresses with spec.tls")
+
+		return nil
+	}
+
+	maxAge := int64(defaultHSTSMaxAge)
+
+	if val, ok := ctx.Annotations[string(models.HSTSMaxAge)]; ok {
+		parsed, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil || parsed < 0 {
+			return &errors.ConverterError{
+				Message: fmt.Sprintf("invalid hsts-max-age %q", val),
+			}
+		}
+
+		maxAge = parsed
+	}
+
+	includeSubdomains := true
+	if val, ok := ctx.Annotations[string(models.HSTSIncludeSubdomains)]; ok {
+		includeSubdomains = strings.TrimSpace(val) == "true"
+	}
+
+	preload := strings.TrimSpace(ctx.Annotations[string(models.HSTSPreload)]) == "true"
+
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "Middleware",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mwName(ctx, "hsts"),
+			Namespace: ctx.Namespace,
+		},
+		Spec: traefik.MiddlewareSpec{
+			Headers: &dynamic.Headers{
+				STSSeconds:           maxAge,
+				STSIncludeSubdomains: includeSubdomains,
+				STSPreload:           preload,
+			},
+		},
+	})
+
+	reportAll(func(name, _ string) { ctx.ReportConverted(name) }, "")
+
+	return nil
+}
@@ -57,7 +57,11 @@ func ProxyCookiePath(ctx configs.Context) error {
 	regex := fmt.Sprintf(`(.*?)(Path=%s)(.*)`, regexp.QuoteMeta(fromPath))
 	replacement := fmt.Sprintf(`$1Path=%s$3`, toPath)
 
-	mw, err := newRewriteResponseHeadersMiddleware(ctx, "Set-Cookie", regex, replacement, "proxy-cookie-path")
+	mw, err := newRewriteResponseHeadersMiddleware(ctx, "proxy-cookie-path", responseHeaders.Rewrite{
+		Header:      "Set-Cookie",
+		Regex:       regex,
+		Replacement: replacement,
+	})
 	if err != nil {
 		return err
 	}
@@ -144,15 +148,9 @@ func parseTwoArgs(val string) (string, string, bool) {
 	return parts[0], parts[1], true
 }
 
-func newRewriteResponseHeadersMiddleware(ctx configs.Context, header, regex, replacement, suffix string) (*traefik.Middleware, error) {
+func newRewriteResponseHeadersMiddleware(ctx configs.Context, suffix string, rewrites ...responseHeaders.Rewrite) (*traefik.Middleware, error) {
 	pluginConfig := responseHeaders.Config{
-		Rewrites: []responseHeaders.Rewrite{
-			{
-				Header:      header,
-				Regex:       regex,
-				Replacement: replacement,
-			},
-		},
+		Rewrites: rewrites,
 	}
 
 	raw, err := json.Marshal(pluginConfig)
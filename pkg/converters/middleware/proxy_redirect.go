@@ -1,16 +1,34 @@
 package middleware
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	responseHeaders "github.com/jamesmcroft/traefik-plugin-rewrite-response-headers"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/errors"
+)
+
+const (
+	proxyRedirectOff     = "off"
+	proxyRedirectDefault = "default"
 )
 
+// redirectVariablePattern matches the nginx variables of a proxy-redirect-to
+// value.
+var redirectVariablePattern = regexp.MustCompile(`\$(\{[A-Za-z0-9_]+\}|[A-Za-z0-9_]+)`)
+
 /* ---------------- PROXY REDIRECT ---------------- */
 
 // ProxyRedirect handles the below annotations.
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/proxy-redirect-from"
 //   - "nginx.ingress.kubernetes.io/proxy-redirect-to"
+//
+// As nginx proxy_redirect, it replaces the "from" prefix of the Location and
+// Refresh response headers with "to".
 func ProxyRedirect(ctx configs.Context) error {
 	ctx.Log.Debug("running converter ProxyRedirect")
 
@@ -24,7 +42,82 @@ func ProxyRedirect(ctx configs.Context) error {
 		return nil
 	}
 
-	mw, err := newRewriteResponseHeadersMiddleware(ctx, "Set-Cookie", redirectFrom, redirectTo, "proxy-redirect")
+	redirectFrom = strings.TrimSpace(redirectFrom)
+	redirectTo = strings.TrimSpace(redirectTo)
+
+	skip := func(msg string) {
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+
+		for _, ann := range []string{annRedirectFrom, annRedirectTo} {
+			if _, ok := ctx.Annotations[ann]; ok {
+				ctx.ReportSkipped(ann, msg)
+			}
+		}
+	}
+
+	switch {
+	case !hasFrom || redirectFrom == "":
+		ctx.ReportIgnored(annRedirectTo, "proxy-redirect-to has no effect without proxy-redirect-from")
+
+		return nil
+	case redirectFrom == proxyRedirectOff:
+		ctx.ReportConverted(annRedirectFrom)
+
+		if hasTo {
+			ctx.ReportIgnored(annRedirectTo, "proxy-redirect-from is off, redirects are not rewritten")
+		}
+
+		return nil
+	case redirectFrom == proxyRedirectDefault:
+		msg := "proxy-redirect-from default only rewrites redirects to the internal upstream address of " +
+			"ingress-nginx, which backends do not send; no rewrite is needed"
+
+		ctx.ReportIgnored(annRedirectFrom, msg)
+
+		if hasTo {
+			ctx.ReportIgnored(annRedirectTo, msg)
+		}
+
+		return nil
+	case strings.HasPrefix(redirectFrom, "~"):
+		skip(fmt.Sprintf("proxy-redirect-from %q is a regular expression, only prefix rewrites are converted", redirectFrom))
+
+		return nil
+	case !hasTo || redirectTo == "":
+		skip("proxy-redirect-from requires proxy-redirect-to, the Location header is not rewritten")
+
+		return nil
+	}
+
+	replacement, err := redirectReplacement(redirectTo)
+	if err != nil {
+		skip(err.Error())
+
+		return nil //nolint:nilerr
+	}
+
+	// If plugins are not enabled, we cannot safely convert this
+	if ctx.Options.DisablePlugins {
+		skip("rewriting the Location header has no native Traefik equivalent; requires a response header rewrite plugin or backend change")
+
+		return nil
+	}
+
+	prefix := regexp.QuoteMeta(redirectFrom)
+
+	mw, err := newRewriteResponseHeadersMiddleware(ctx, "proxy-redirect",
+		responseHeaders.Rewrite{
+			Header:      "Location",
+			Regex:       "^" + prefix,
+			Replacement: replacement,
+		},
+		// Refresh: 5; url=http://backend/path
+		responseHeaders.Rewrite{
+			Header:      "Refresh",
+			Regex:       `^([^;]*;\s*(?i:url)=)` + prefix,
+			Replacement: "${1}" + replacement,
+		},
+	)
 	if err != nil {
 		return err
 	}
@@ -32,8 +125,33 @@ func ProxyRedirect(ctx configs.Context) error {
 	ctx.Result.Middlewares = append(ctx.Result.Middlewares, mw)
 
 	ctx.ReportConverted(annRedirectFrom)
-
 	ctx.ReportConverted(annRedirectTo)
 
 	return nil
 }
+
+// redirectReplacement escapes the proxy-redirect-to value for a regex
+// replacement. The request host variables map to the {RequestHost}
+// placeholder of the plugin, other nginx variables cannot be converted.
+func redirectReplacement(redirectTo string) (string, error) {
+	var unsupported []string
+
+	replacement := redirectVariablePattern.ReplaceAllStringFunc(redirectTo, func(variable string) string {
+		switch strings.Trim(variable, "${}") {
+		case "host", "http_host":
+			return "{RequestHost}"
+		default:
+			unsupported = append(unsupported, variable)
+
+			return variable
+		}
+	})
+
+	if len(unsupported) != 0 {
+		return "", &errors.ConverterError{Message: fmt.Sprintf(
+			"proxy-redirect-to %q uses nginx variables %s, which Traefik cannot substitute",
+			redirectTo, strings.Join(unsupported, ", "))}
+	}
+
+	return strings.ReplaceAll(replacement, "$", "$$"), nil
+}
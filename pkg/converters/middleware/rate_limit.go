@@ -181,35 +181,3 @@ func LimitConnections(ctx configs.Context) error {
 
 	return nil
 }
-
-/* ---------------- PROXY TIMEOUTS (warnings) ---------------- */
-
-// ProxyTimeouts handles the below annotations.
-// Annotations:
-//   - "nginx.ingress.kubernetes.io/proxy-read-timeout"
-//   - "nginx.ingress.kubernetes.io/proxy-send-timeout"
-//
-// These cannot be set per-route in Traefik.
-// They should be configured via ServersTransport or static config.
-func ProxyTimeouts(ctx configs.Context) {
-	ctx.Log.Debug("running converter ProxyTimeouts")
-
-	annRead := string(models.ProxyReadTimeout)
-	annSend := string(models.ProxySendTimeout)
-
-	if _, ok := ctx.Annotations[annRead]; ok {
-		msg := "proxy-read-timeout cannot be set per-route in Traefik; " +
-			"configure via ServersTransport forwardingTimeouts.responseHeaderTimeout in dynamic config"
-
-		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
-		ctx.ReportSkipped(annRead, msg)
-	}
-
-	if _, ok := ctx.Annotations[annSend]; ok {
-		msg := "proxy-send-timeout cannot be set per-route in Traefik; " +
-			"configure via ServersTransport forwardingTimeouts in dynamic config"
-
-		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
-		ctx.ReportSkipped(annSend, msg)
-	}
-}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// defaultNextUpstream is the ingress-nginx default for proxy-next-upstream.
+	defaultNextUpstream = "error timeout"
+	// defaultNextUpstreamTries is the ingress-nginx default for proxy-next-upstream-tries.
+	defaultNextUpstreamTries = 3
+)
+
+/* ---------------- RETRY ---------------- */
+
+// Retry handles the below annotations.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/proxy-next-upstream"
+//   - "nginx.ingress.kubernetes.io/proxy-next-upstream-tries"
+//   - "nginx.ingress.kubernetes.io/proxy-next-upstream-timeout"
+//
+// Traefik only retries requests the backend did not reply to, so of the nginx
+// conditions only "error" and connection timeouts carry over. initialInterval
+// is set to 0: nginx moves on to the next upstream at once, and so does
+// Traefik without a backoff.
+func Retry(ctx configs.Context) {
+	ctx.Log.Debug("running converter Retry")
+
+	annNext := string(models.ProxyNextUpstream)
+	annTries := string(models.ProxyNextUpstreamTries)
+	annTimeout := string(models.ProxyNextUpstreamTimeout)
+
+	next, okNext := ctx.Annotations[annNext]
+	tries, okTries := ctx.Annotations[annTries]
+	_, okTimeout := ctx.Annotations[annTimeout]
+
+	if !okNext && !okTries && !okTimeout {
+		return
+	}
+
+	ignoreRest := func(msg string) {
+		for _, ann := range []string{annTries, annTimeout} {
+			if _, ok := ctx.Annotations[ann]; ok {
+				ctx.ReportIgnored(ann, msg)
+			}
+		}
+	}
+
+	// The Retry middleware is reported on proxy-next-upstream, or on the
+	// annotation that brought in its default conditions.
+	retryAnn := annNext
+
+	switch {
+	case okNext:
+	case okTries:
+		retryAnn = annTries
+	default:
+		retryAnn = annTimeout
+	}
+
+	if !okNext || strings.TrimSpace(next) == "" {
+		next = defaultNextUpstream
+	}
+
+	conditions := strings.Fields(next)
+	if len(conditions) == 1 && conditions[0] == "off" {
+		ctx.ReportConverted(annNext)
+		ignoreRest("proxy-next-upstream is off, requests are not retried")
+
+		return
+	}
+
+	attempts := defaultNextUpstreamTries
+
+	if okTries {
+		parsed, err := strconv.Atoi(strings.TrimSpace(tries))
+
+		switch {
+		case err != nil || parsed < 0:
+			msg := fmt.Sprintf("invalid proxy-next-upstream-tries %q, expected a number", tries)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportSkipped(annTries, msg)
+		case parsed == 0:
+			msg := fmt.Sprintf("proxy-next-upstream-tries 0 is unlimited in nginx, Traefik needs a limit: %d attempts are used",
+				defaultNextUpstreamTries)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annTries, msg)
+		default:
+			attempts = parsed
+
+			if attempts < 2 || retryAnn != annTries {
+				ctx.ReportConverted(annTries)
+			}
+		}
+	}
+
+	if attempts < 2 {
+		if okNext {
+			ctx.ReportConverted(annNext)
+		}
+
+		ignoreRest("proxy-next-upstream-tries allows a single attempt, requests are not retried")
+
+		return
+	}
+
+	if unsupported := retryConditionWarnings(conditions); len(unsupported) != 0 {
+		msg := "proxy-next-upstream converted to a Retry middleware, Traefik only retries requests the backend did not reply to; " +
+			strings.Join(unsupported, "; ")
+		if !okNext {
+			msg = fmt.Sprintf("proxy-next-upstream is not set, its ingress-nginx default %q was converted to a Retry middleware, "+
+				"Traefik only retries requests the backend did not reply to; %s", defaultNextUpstream, strings.Join(unsupported, "; "))
+		}
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(retryAnn, msg)
+	} else if okNext {
+		ctx.ReportConverted(annNext)
+	}
+
+	if okTimeout {
+		if timeout := strings.TrimSpace(ctx.Annotations[annTimeout]); timeout == "0" {
+			if retryAnn != annTimeout {
+				ctx.ReportConverted(annTimeout)
+			}
+		} else {
+			msg := "proxy-next-upstream-timeout has no Traefik equivalent, the time spent on retries is not limited"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportSkipped(annTimeout, msg)
+		}
+	}
+
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "Middleware",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mwName(ctx, "retry"),
+			Namespace: ctx.Namespace,
+		},
+		Spec: traefik.MiddlewareSpec{
+			Retry: &traefik.Retry{
+				Attempts:        attempts,
+				InitialInterval: intstr.FromInt32(0),
+			},
+		},
+	})
+}
+
+// retryConditionWarnings returns a message for every proxy-next-upstream
+// condition Traefik does not retry on the same way.
+func retryConditionWarnings(conditions []string) []string {
+	var warnings []string
+
+	nonIdempotent := false
+
+	for _, condition := range conditions {
+		switch {
+		case condition == "error":
+		case condition == "non_idempotent":
+			nonIdempotent = true
+		case condition == "timeout":
+			warnings = append(warnings, "timeout: only connection timeouts are retried, not read timeouts")
+		case condition == "invalid_header", strings.HasPrefix(condition, "http_"):
+			warnings = append(warnings, condition+": Traefik does not retry on responses")
+		default:
+			warnings = append(warnings, condition+": unknown condition")
+		}
+	}
+
+	if !nonIdempotent {
+		warnings = append(warnings, "non_idempotent is not set but Traefik also retries POST, LOCK and PATCH requests")
+	}
+
+	return warnings
+}
@@ -1,110 +1,137 @@
 package middleware
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/nginx"
 )
 
+// serverSnippetKind groups the server-snippet directives that share an
+// explanation of why they were not converted.
+type serverSnippetKind struct {
+	match   func(name string) bool
+	message string
+}
+
+var serverSnippetKinds = []serverSnippetKind{
+	{
+		// Header-only server-snippet
+		match: func(name string) bool { return name == "add_header" || name == "more_set_headers" },
+		message: "server-snippet sets response headers. " +
+			"These were not auto-converted because server-snippet applies " +
+			"at NGINX server scope. Consider moving them to " +
+			"nginx.ingress.kubernetes.io/configuration-snippet " +
+			"or converting them manually to a Traefik Headers middleware.",
+	},
+	{
+		// Header buffer tuning (static Traefik config)
+		match: func(name string) bool {
+			return name == "client_header_buffer_size" || name == "large_client_header_buffers"
+		},
+		message: "server-snippet configures request header buffer sizes. " +
+			"Traefik does not support per-route header buffer tuning. " +
+			"Equivalent settings must be configured globally on entryPoints " +
+			"(e.g. http.maxHeaderBytes) in Traefik static configuration.",
+	},
+	{
+		// Upstream timeouts (proxy_connect_timeout / proxy_read_timeout)
+		match: func(name string) bool {
+			return name == "proxy_connect_timeout" || name == "proxy_read_timeout"
+		},
+		message: "server-snippet configures upstream timeouts. " +
+			"Move them to the nginx.ingress.kubernetes.io/proxy-connect-timeout and proxy-read-timeout " +
+			"annotations, which are converted to the forwardingTimeouts of the ServersTransport of the Ingress.",
+	},
+	{
+		// Request send timeout (proxy_send_timeout)
+		match: func(name string) bool { return name == "proxy_send_timeout" },
+		message: "server-snippet configures proxy_send_timeout, which limits the time between two writes " +
+			"of the request to the backend. Traefik has no timeout while sending the request, " +
+			"so it cannot be converted.",
+	},
+	{
+		// Client timeouts (static Traefik config)
+		match: func(name string) bool { return name == "send_timeout" },
+		message: "server-snippet configures the timeout of writes to the client. " +
+			"Traefik does not support per-route client timeouts. Equivalent settings must be " +
+			"configured globally on entryPoints (e.g. transport.respondingTimeouts.writeTimeout) " +
+			"in Traefik static configuration.",
+	},
+	{
+		// TLS knobs (ssl_* / proxy_ssl_*)
+		match: func(name string) bool {
+			return strings.HasPrefix(name, "ssl_") || strings.HasPrefix(name, "proxy_ssl_")
+		},
+		message: "server-snippet configures TLS-related directives. " +
+			"These cannot be safely auto-converted. In Traefik, use TLSOption " +
+			"and/or ServersTransport for TLS configuration.",
+	},
+	{
+		// Rate limiting (limit_req / limit_conn)
+		match: func(name string) bool {
+			return strings.HasPrefix(name, "limit_req") || strings.HasPrefix(name, "limit_conn")
+		},
+		message: "server-snippet configures NGINX rate limiting (limit_req/limit_conn). " +
+			"Traefik provides a RateLimit middleware, but semantics differ and this cannot be " +
+			"auto-converted safely.",
+	},
+	{
+		match:   func(string) bool { return true },
+		message: "server-snippet injects raw NGINX server configuration which has no Traefik equivalent; skipped",
+	},
+}
+
 /* ---------------- SERVER SNIPPET ---------------- */
 
 // ServerSnippet handles the below annotations.
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/server-snippet"
+//
+// A server-snippet is never converted. Its directives are parsed and
+// reported by kind, each message starting with the lines it covers.
 func ServerSnippet(ctx configs.Context) {
 	ctx.Log.Debug("running converter ServerSnippet")
 
-	snippet, ok := ctx.Annotations[string(models.ServerSnippet)]
-	if !ok || strings.TrimSpace(snippet) == "" {
-		return
-	}
-
-	// 1) Header-only server-snippet (heuristic)
-	if isOnlyAddHeader(snippet) {
-		warningMessage := "server-snippet contains only add_header directives. " +
-			"These were not auto-converted because server-snippet applies " +
-			"at NGINX server scope. Consider moving them to " +
-			"nginx.ingress.kubernetes.io/configuration-snippet " +
-			"or converting them manually to a Traefik Headers middleware."
-
-		ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
-
-		ctx.ReportSkipped(string(models.ServerSnippet), warningMessage)
-
-		return
-	}
-
-	// 2) Header buffer tuning (static Traefik config)
-	if strings.Contains(snippet, "client_header_buffer_size") ||
-		strings.Contains(snippet, "large_client_header_buffers") {
-		ctx.Result.Warnings = append(ctx.Result.Warnings,
-			"server-snippet configures request header buffer sizes. "+
-				"Traefik does not support per-route header buffer tuning. "+
-				"Equivalent settings must be configured globally on entryPoints "+
-				"(e.g. http.maxHeaderBytes) in Traefik static configuration.",
-		)
-
-		return
-	}
-
-	// 3) Timeout tuning (proxy / send timeouts)
-	if strings.Contains(snippet, "proxy_read_timeout") ||
-		strings.Contains(snippet, "proxy_send_timeout") ||
-		strings.Contains(snippet, "send_timeout") {
-		warningMessage := "server-snippet configures timeout settings (proxy/send timeouts). " +
-			"These cannot be set per-route in Traefik. Consider using ServersTransport " +
-			"(e.g. forwardingTimeouts) in dynamic configuration or static config instead."
-
-		ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
-		ctx.ReportSkipped(string(models.ServerSnippet), warningMessage)
+	ann := string(models.ServerSnippet)
 
+	snippet, ok := ctx.Annotations[ann]
+	if !ok || strings.TrimSpace(snippet) == "" {
 		return
 	}
 
-	// 4) TLS knobs (ssl_* / proxy_ssl_*)
-	if strings.Contains(snippet, "ssl_") || strings.Contains(snippet, "proxy_ssl_") {
-		warningMessage := "server-snippet configures TLS-related directives. " +
-			"These cannot be safely auto-converted. In Traefik, use TLSOption " +
-			"and/or ServersTransport for TLS configuration."
+	directives, err := nginx.Parse(snippet)
+	if err != nil {
+		msg := "server-snippet is not valid NGINX configuration and was skipped: " + err.Error()
 
-		ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
-		ctx.ReportSkipped(string(models.ServerSnippet), warningMessage)
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(ann, msg)
 
 		return
 	}
 
-	// 5) Rate limiting (limit_req / limit_conn)
-	if strings.Contains(snippet, "limit_req") || strings.Contains(snippet, "limit_conn") {
-		warningMessage := "server-snippet configures NGINX rate limiting (limit_req/limit_conn). " +
-			"Traefik provides a RateLimit middleware, but semantics differ and this cannot be " +
-			"auto-converted safely."
+	found := make([][]string, len(serverSnippetKinds))
 
-		ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
-		ctx.ReportSkipped(string(models.ServerSnippet), warningMessage)
+	for _, directive := range directives {
+		for i, kind := range serverSnippetKinds {
+			if kind.match(directive.Name) {
+				found[i] = append(found[i], fmt.Sprintf("line %d (%s)", directive.Line, directive.Name))
 
-		return
+				break
+			}
+		}
 	}
 
-	warningMessage := "server-snippet injects raw NGINX server configuration which has no Traefik equivalent; skipped"
-
-	ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
-
-	ctx.ReportSkipped(string(models.ServerSnippet), warningMessage)
-}
-
-func isOnlyAddHeader(snippet string) bool {
-	lines := strings.Split(snippet, "\n")
-	for _, l := range lines {
-		line := strings.TrimSpace(l)
-		if line == "" {
+	for i, kind := range serverSnippetKinds {
+		if len(found[i]) == 0 {
 			continue
 		}
 
-		if !strings.HasPrefix(line, "add_header") {
-			return false
-		}
-	}
+		warningMessage := strings.Join(found[i], ", ") + ": " + kind.message
 
-	return true
+		ctx.Result.Warnings = append(ctx.Result.Warnings, warningMessage)
+		ctx.ReportSkipped(ann, warningMessage)
+	}
 }
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
@@ -10,12 +11,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// RedirectRouteSuffix is appended to the name of a TLS IngressRoute to name
+// its companion on the HTTP entry point that only redirects to HTTPS.
+const RedirectRouteSuffix = "-redirect"
+
 /* ---------------- REDIRECT ---------------- */
 
 // SSLRedirect handles the below annotations.
 // Annotations:
 //   - "nginx.ingress.kubernetes.io/ssl-redirect"
 //   - "nginx.ingress.kubernetes.io/force-ssl-redirect"
+//
+// nginx serves the hosts with a certificate on both ports. Port 80 either
+// redirects to HTTPS, by default, or serves the same locations when
+// ssl-redirect is "false". Each TLS IngressRoute therefore gets a companion
+// on the HTTP entry point doing the same.
 func SSLRedirect(ctx configs.Context) {
 	ctx.Log.Debug("running converter SSLRedirect")
 
@@ -26,50 +36,51 @@ func SSLRedirect(ctx configs.Context) {
 
 	force, annForceSslRedirectOk := ctx.Annotations[annForceSslRedirect]
 
-	if !annSSLRedirectOk && !annForceSslRedirectOk {
-		return
+	// ssl-redirect defaults to true in ingress-nginx.
+	sslOn := !annSSLRedirectOk || strings.TrimSpace(ssl) == "true"
+	forceOn := strings.TrimSpace(force) == "true"
+
+	tlsRoutes := make([]*traefik.IngressRoute, 0, len(ctx.Result.IngressRoutes))
+	plainRoutes := make([]*traefik.IngressRoute, 0)
+
+	for _, ingressRoute := range ctx.Result.IngressRoutes {
+		if ingressRoute.Spec.TLS != nil {
+			tlsRoutes = append(tlsRoutes, ingressRoute)
+		} else {
+			plainRoutes = append(plainRoutes, ingressRoute)
+		}
 	}
 
-	if ssl != "true" && force != "true" {
+	if !sslOn && !forceOn {
+		// Port 80 serves the TLS hosts like port 443.
+		for _, ingressRoute := range tlsRoutes {
+			ctx.Result.IngressRoutes = append(ctx.Result.IngressRoutes, httpRoute(ctx, ingressRoute, "-http", nil))
+		}
+
 		if annSSLRedirectOk {
-			ctx.ReportSkipped(annSSLRedirect, fmt.Sprintf("%s is not set to true", annSSLRedirect))
+			ctx.ReportConverted(annSSLRedirect)
 		}
 
 		if annForceSslRedirectOk {
-			ctx.ReportSkipped(annForceSslRedirect, fmt.Sprintf("%s is not set to true", annForceSslRedirect))
+			ctx.ReportConverted(annForceSslRedirect)
 		}
 
 		return
 	}
 
-	// When the Ingress has spec.tls, the IngressRoute will use entryPoints=[websecure],
-	// so a redirectScheme middleware is useless (traffic is already HTTPS).
-	// The HTTP→HTTPS redirect should be handled at the Traefik entrypoint level.
-	// if len(ctx.Ingress.Spec.TLS) > 0 {
-	// 	msg := "IngressRoute already uses entryPoints=[websecure] due to spec.tls; " +
-	// 		"configure HTTP→HTTPS redirect via Traefik static config " +
-	// 		"(entryPoints.web.http.redirections.entryPoint.to=websecure) " +
-	// 		"or create a separate IngressRoute on the web entryPoint with this middleware"
-
-	// 	ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
-
-	// 	if annSSLRedirectOk {
-	// 		ctx.ReportSkipped(annSSLRedirect, msg)
-	// 	}
-
-	// 	if annForceSslRedirectOk {
-	// 		ctx.ReportSkipped(annForceSslRedirect, msg)
-	// 	}
+	if len(tlsRoutes) == 0 && (!forceOn || len(plainRoutes) == 0) {
+		if annSSLRedirectOk {
+			ctx.ReportIgnored(annSSLRedirect, "the Ingress has no spec.tls, nginx only redirects hosts served with a certificate")
+		}
 
-	// 	return
-	// }
+		if annForceSslRedirectOk {
+			ctx.ReportConverted(annForceSslRedirect)
+		}
 
-	if len(ctx.Result.IngressRoutes) != 1 {
-		ctx.ReportSkipped(annSSLRedirect, fmt.Sprintf("len(IngressRoutes) is %d, cannot determine which IngressRoute to apply the redirect to", len(ctx.Result.IngressRoutes)))
 		return
 	}
 
-	ctx.Result.Middlewares = append(ctx.Result.Middlewares, &traefik.Middleware{
+	redirect := &traefik.Middleware{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: traefik.SchemeGroupVersion.String(),
 			Kind:       "Middleware",
@@ -84,26 +95,67 @@ func SSLRedirect(ctx configs.Context) {
 				Permanent: true,
 			},
 		},
-	})
-
-	// deep copy of ctx.Result.IngressRoutes[0]
-	copy := *ctx.Result.IngressRoutes[0].DeepCopy()
-	ctx.Result.IngressRoutes = append(ctx.Result.IngressRoutes, &copy)
-	// Remove spec.TLS
-	ctx.Result.IngressRoutes[1].Spec.TLS = nil
-	// Change entrypoint to web
-	ctx.Result.IngressRoutes[1].Spec.EntryPoints = []string{"web"}
-	// Add middleware "https-redirect" to all Rules
-	redirectRef := traefik.MiddlewareRef{Name: ctx.Result.Middlewares[len(ctx.Result.Middlewares)-1].GetName()}
-	for i := range ctx.Result.IngressRoutes[1].Spec.Routes {
-		ctx.Result.IngressRoutes[1].Spec.Routes[i].Middlewares = append(ctx.Result.IngressRoutes[1].Spec.Routes[i].Middlewares, redirectRef)
 	}
 
-	if annSSLRedirectOk {
-		ctx.ReportConverted(annSSLRedirect)
+	ctx.Result.Middlewares = append(ctx.Result.Middlewares, redirect)
+
+	redirectRef := traefik.MiddlewareRef{Name: redirect.GetName()}
+
+	// Hosts with a certificate get an HTTP IngressRoute that only redirects.
+	for _, ingressRoute := range tlsRoutes {
+		ctx.Result.IngressRoutes = append(ctx.Result.IngressRoutes,
+			httpRoute(ctx, ingressRoute, RedirectRouteSuffix, []traefik.MiddlewareRef{redirectRef}))
 	}
 
-	if annForceSslRedirectOk {
+	// Without one, nginx only redirects with force-ssl-redirect, typically
+	// behind a load balancer terminating TLS, whose requests must keep being
+	// served.
+	if forceOn && len(plainRoutes) != 0 {
+		// The redirect runs first, so it answers before auth or rate limits.
+		for _, ingressRoute := range plainRoutes {
+			for i := range ingressRoute.Spec.Routes {
+				route := &ingressRoute.Spec.Routes[i]
+				route.Middlewares = append([]traefik.MiddlewareRef{redirectRef}, route.Middlewares...)
+			}
+		}
+
+		msg := fmt.Sprintf("force-ssl-redirect on hosts without spec.tls redirects the requests whose X-Forwarded-Proto is not https; "+
+			"set forwardedHeaders.trustedIPs on the %s entry point to the TLS-terminating load balancer, "+
+			"otherwise Traefik overwrites the header and every request is redirected", ctx.HTTPEntryPoint())
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportWarning(annForceSslRedirect, msg)
+	} else if annForceSslRedirectOk {
 		ctx.ReportConverted(annForceSslRedirect)
 	}
+
+	switch {
+	case annSSLRedirectOk && sslOn:
+		ctx.ReportConverted(annSSLRedirect)
+	case annSSLRedirectOk:
+		ctx.ReportIgnored(annSSLRedirect, "force-ssl-redirect redirects every host")
+	case !forceOn:
+		ctx.ReportWarning(annSSLRedirect, "not set on the Ingress, the ingress-nginx default true is assumed and HTTP "+
+			"requests are redirected to HTTPS; load the controller ConfigMap if it disables ssl-redirect")
+	}
+}
+
+// httpRoute returns the companion of a TLS IngressRoute on the HTTP entry
+// point. Its routes keep the matchers and priorities of the originals, and
+// run middlewares instead of theirs unless it is nil.
+func httpRoute(ctx configs.Context, ingressRoute *traefik.IngressRoute, suffix string,
+	middlewares []traefik.MiddlewareRef,
+) *traefik.IngressRoute {
+	companion := ingressRoute.DeepCopy()
+	companion.Name = ingressRoute.Name + suffix
+	companion.Spec.EntryPoints = []string{ctx.HTTPEntryPoint()}
+	companion.Spec.TLS = nil
+
+	if middlewares != nil {
+		for i := range companion.Spec.Routes {
+			companion.Spec.Routes[i].Middlewares = middlewares
+		}
+	}
+
+	return companion
 }
@@ -2,61 +2,110 @@ package models
 
 type Annotation string
 
+// Prefix is the default ingress-nginx annotation prefix. Annotations using
+// another prefix (ingress-nginx --annotations-prefix) are normalised to it
+// before the converters run.
+const Prefix = "nginx.ingress.kubernetes.io/"
+
 const (
-	AuthType                 Annotation = "nginx.ingress.kubernetes.io/auth-type"
-	AuthSecret               Annotation = "nginx.ingress.kubernetes.io/auth-secret" //nolint:gosec
-	AuthRealm                Annotation = "nginx.ingress.kubernetes.io/auth-realm"
-	AuthTLSVerifyClient      Annotation = "nginx.ingress.kubernetes.io/auth-tls-verify-client"
-	AuthTLSSecret            Annotation = "nginx.ingress.kubernetes.io/auth-tls-secret" //nolint:gosec
-	AuthURL                  Annotation = "nginx.ingress.kubernetes.io/auth-url"
-	ProxyBodySize            Annotation = "nginx.ingress.kubernetes.io/proxy-body-size"
-	ConfigurationSnippet     Annotation = "nginx.ingress.kubernetes.io/configuration-snippet"
-	EnableCORS               Annotation = "nginx.ingress.kubernetes.io/enable-cors"
-	CorsAllowOrigin          Annotation = "nginx.ingress.kubernetes.io/cors-allow-origin"
-	CorsAllowMethods         Annotation = "nginx.ingress.kubernetes.io/cors-allow-methods"
-	CorsAllowHeaders         Annotation = "nginx.ingress.kubernetes.io/cors-allow-headers"
-	CorsAllowCredentials     Annotation = "nginx.ingress.kubernetes.io/cors-allow-credentials" //nolint:gosec
-	CorsMaxAge               Annotation = "nginx.ingress.kubernetes.io/cors-max-age"
-	CorsExposeHeaders        Annotation = "nginx.ingress.kubernetes.io/cors-expose-headers"
-	ProxyBuffering           Annotation = "nginx.ingress.kubernetes.io/proxy-buffering"
-	ServiceUpstream          Annotation = "nginx.ingress.kubernetes.io/service-upstream"
-	EnableOpentracing        Annotation = "nginx.ingress.kubernetes.io/enable-opentracing"
-	EnableOpentelemetry      Annotation = "nginx.ingress.kubernetes.io/enable-opentelemetry"
-	BackendProtocol          Annotation = "nginx.ingress.kubernetes.io/backend-protocol"
-	GrpcBackend              Annotation = "nginx.ingress.kubernetes.io/grpc-backend"
-	ProxyBufferSize          Annotation = "nginx.ingress.kubernetes.io/proxy-buffer-size"
-	LimitConnections         Annotation = "nginx.ingress.kubernetes.io/limit-connections"
-	LimitRPS                 Annotation = "nginx.ingress.kubernetes.io/limit-rps"
-	LimitRPM                 Annotation = "nginx.ingress.kubernetes.io/limit-rpm"
-	LimitBurstMultiplier     Annotation = "nginx.ingress.kubernetes.io/limit-burst-multiplier"
-	ProxyReadTimeout         Annotation = "nginx.ingress.kubernetes.io/proxy-read-timeout"
-	ProxySendTimeout         Annotation = "nginx.ingress.kubernetes.io/proxy-send-timeout"
-	RewriteTarget            Annotation = "nginx.ingress.kubernetes.io/rewrite-target"
-	AppRoot                  Annotation = "nginx.ingress.kubernetes.io/app-root"
-	PermanentRedirect        Annotation = "nginx.ingress.kubernetes.io/permanent-redirect"
-	SSLRedirect              Annotation = "nginx.ingress.kubernetes.io/ssl-redirect"
-	ForceSSLRedirect         Annotation = "nginx.ingress.kubernetes.io/force-ssl-redirect"
-	UpstreamVhost            Annotation = "nginx.ingress.kubernetes.io/upstream-vhost"
-	ProxyRedirectFrom        Annotation = "nginx.ingress.kubernetes.io/proxy-redirect-from"
-	ProxyRedirectTo          Annotation = "nginx.ingress.kubernetes.io/proxy-redirect-to"
-	ProxyCookiePath          Annotation = "nginx.ingress.kubernetes.io/proxy-cookie-path"
-	ServerSnippet            Annotation = "nginx.ingress.kubernetes.io/server-snippet"
-	UnderscoresInHeaders     Annotation = "nginx.ingress.kubernetes.io/enable-underscores-in-headers"
-	UseRegex                 Annotation = "nginx.ingress.kubernetes.io/use-regex"
-	ClientHeaderBufferSize   Annotation = "nginx.ingress.kubernetes.io/client-header-buffer-size"
-	LargeClientHeaderBuffers Annotation = "nginx.ingress.kubernetes.io/large-client-header-buffers"
-	WhitelistSourceRange     Annotation = "nginx.ingress.kubernetes.io/whitelist-source-range"
-
-	// HSTS annotations — handled natively by nginx ingress controller but
-	// not translatable per-Ingress in Traefik (HSTS is set via headers middleware
-	// or via the configuration-snippet add_header directive).
-	HSTS                  Annotation = "nginx.ingress.kubernetes.io/hsts"
-	HSTSIncludeSubdomains Annotation = "nginx.ingress.kubernetes.io/hsts-include-subdomains"
-	HSTSMaxAge            Annotation = "nginx.ingress.kubernetes.io/hsts-max-age"
-	HSTSPreload           Annotation = "nginx.ingress.kubernetes.io/hsts-preload"
+	AuthType                 Annotation = Prefix + "auth-type"
+	AuthSecret               Annotation = Prefix + "auth-secret" //nolint:gosec
+	AuthRealm                Annotation = Prefix + "auth-realm"
+	AuthTLSVerifyClient      Annotation = Prefix + "auth-tls-verify-client"
+	AuthTLSSecret            Annotation = Prefix + "auth-tls-secret" //nolint:gosec
+	AuthURL                  Annotation = Prefix + "auth-url"
+	AuthResponseHeaders      Annotation = Prefix + "auth-response-headers"
+	AuthRequestRedirect      Annotation = Prefix + "auth-request-redirect"
+	AuthSignin               Annotation = Prefix + "auth-signin"
+	AuthSigninRedirectParam  Annotation = Prefix + "auth-signin-redirect-param"
+	AuthMethod               Annotation = Prefix + "auth-method"
+	AuthProxySetHeaders      Annotation = Prefix + "auth-proxy-set-headers"
+	AuthCacheKey             Annotation = Prefix + "auth-cache-key"
+	AuthCacheDuration        Annotation = Prefix + "auth-cache-duration"
+	AuthKeepalive            Annotation = Prefix + "auth-keepalive"
+	AuthSnippet              Annotation = Prefix + "auth-snippet"
+	EnableGlobalAuth         Annotation = Prefix + "enable-global-auth"
+	ProxyBodySize            Annotation = Prefix + "proxy-body-size"
+	ConfigurationSnippet     Annotation = Prefix + "configuration-snippet"
+	EnableCORS               Annotation = Prefix + "enable-cors"
+	CorsAllowOrigin          Annotation = Prefix + "cors-allow-origin"
+	CorsAllowMethods         Annotation = Prefix + "cors-allow-methods"
+	CorsAllowHeaders         Annotation = Prefix + "cors-allow-headers"
+	CorsAllowCredentials     Annotation = Prefix + "cors-allow-credentials" //nolint:gosec
+	CorsMaxAge               Annotation = Prefix + "cors-max-age"
+	CorsExposeHeaders        Annotation = Prefix + "cors-expose-headers"
+	ProxyBuffering           Annotation = Prefix + "proxy-buffering"
+	ServiceUpstream          Annotation = Prefix + "service-upstream"
+	EnableOpentracing        Annotation = Prefix + "enable-opentracing"
+	EnableOpentelemetry      Annotation = Prefix + "enable-opentelemetry"
+	BackendProtocol          Annotation = Prefix + "backend-protocol"
+	GrpcBackend              Annotation = Prefix + "grpc-backend"
+	ProxySSLSecret           Annotation = Prefix + "proxy-ssl-secret" //nolint:gosec
+	ProxySSLVerify           Annotation = Prefix + "proxy-ssl-verify"
+	ProxySSLVerifyDepth      Annotation = Prefix + "proxy-ssl-verify-depth"
+	ProxySSLName             Annotation = Prefix + "proxy-ssl-name"
+	ProxySSLServerName       Annotation = Prefix + "proxy-ssl-server-name"
+	ProxySSLProtocols        Annotation = Prefix + "proxy-ssl-protocols"
+	ProxyBufferSize          Annotation = Prefix + "proxy-buffer-size"
+	LimitConnections         Annotation = Prefix + "limit-connections"
+	LimitRPS                 Annotation = Prefix + "limit-rps"
+	LimitRPM                 Annotation = Prefix + "limit-rpm"
+	LimitBurstMultiplier     Annotation = Prefix + "limit-burst-multiplier"
+	ProxyConnectTimeout      Annotation = Prefix + "proxy-connect-timeout"
+	ProxyReadTimeout         Annotation = Prefix + "proxy-read-timeout"
+	ProxySendTimeout         Annotation = Prefix + "proxy-send-timeout"
+	ProxyNextUpstream        Annotation = Prefix + "proxy-next-upstream"
+	ProxyNextUpstreamTries   Annotation = Prefix + "proxy-next-upstream-tries"
+	ProxyNextUpstreamTimeout Annotation = Prefix + "proxy-next-upstream-timeout"
+	CustomHTTPErrors         Annotation = Prefix + "custom-http-errors"
+	DefaultBackend           Annotation = Prefix + "default-backend"
+	RewriteTarget            Annotation = Prefix + "rewrite-target"
+	AppRoot                  Annotation = Prefix + "app-root"
+	PermanentRedirect        Annotation = Prefix + "permanent-redirect"
+	SSLRedirect              Annotation = Prefix + "ssl-redirect"
+	ForceSSLRedirect         Annotation = Prefix + "force-ssl-redirect"
+	UpstreamVhost            Annotation = Prefix + "upstream-vhost"
+	ProxyRedirectFrom        Annotation = Prefix + "proxy-redirect-from"
+	ProxyRedirectTo          Annotation = Prefix + "proxy-redirect-to"
+	ProxyCookiePath          Annotation = Prefix + "proxy-cookie-path"
+	ServerSnippet            Annotation = Prefix + "server-snippet"
+	UnderscoresInHeaders     Annotation = Prefix + "enable-underscores-in-headers"
+	UseRegex                 Annotation = Prefix + "use-regex"
+	ClientHeaderBufferSize   Annotation = Prefix + "client-header-buffer-size"
+	LargeClientHeaderBuffers Annotation = Prefix + "large-client-header-buffers"
+	WhitelistSourceRange     Annotation = Prefix + "whitelist-source-range"
+
+	// HSTS annotations — handled natively by nginx ingress controller, in
+	// Traefik HSTS is set via a headers middleware.
+	HSTS                  Annotation = Prefix + "hsts"
+	HSTSIncludeSubdomains Annotation = Prefix + "hsts-include-subdomains"
+	HSTSMaxAge            Annotation = Prefix + "hsts-max-age"
+	HSTSPreload           Annotation = Prefix + "hsts-preload"
+
+	// Canary annotations — a canary Ingress is merged into the primary
+	// Ingress serving the same host and path.
+	Canary                Annotation = Prefix + "canary"
+	CanaryWeight          Annotation = Prefix + "canary-weight"
+	CanaryWeightTotal     Annotation = Prefix + "canary-weight-total"
+	CanaryByHeader        Annotation = Prefix + "canary-by-header"
+	CanaryByHeaderValue   Annotation = Prefix + "canary-by-header-value"
+	CanaryByHeaderPattern Annotation = Prefix + "canary-by-header-pattern"
+	CanaryByCookie        Annotation = Prefix + "canary-by-cookie"
+
+	// Session affinity annotations — converted to sticky cookies on the
+	// IngressRoute services.
+	Affinity                     Annotation = Prefix + "affinity"
+	AffinityMode                 Annotation = Prefix + "affinity-mode"
+	SessionCookieName            Annotation = Prefix + "session-cookie-name"
+	SessionCookiePath            Annotation = Prefix + "session-cookie-path"
+	SessionCookieSameSite        Annotation = Prefix + "session-cookie-samesite"
+	SessionCookieSecure          Annotation = Prefix + "session-cookie-secure"
+	SessionCookieMaxAge          Annotation = Prefix + "session-cookie-max-age"
+	SessionCookieExpires         Annotation = Prefix + "session-cookie-expires"
+	SessionCookieChangeOnFailure Annotation = Prefix + "session-cookie-change-on-failure"
 
 	// WWW redirect — not translatable automatically.
-	FromToWWWRedirect Annotation = "nginx.ingress.kubernetes.io/from-to-www-redirect"
+	FromToWWWRedirect Annotation = Prefix + "from-to-www-redirect"
 
 	// cert-manager annotations (used by ingress-shim to auto-create Certificate resources).
 	CertManagerClusterIssuer Annotation = "cert-manager.io/cluster-issuer"
@@ -76,6 +125,17 @@ var NginxAnnotations = []Annotation{
 	AuthTLSVerifyClient,
 	AuthTLSSecret,
 	AuthURL,
+	AuthResponseHeaders,
+	AuthRequestRedirect,
+	AuthSignin,
+	AuthSigninRedirectParam,
+	AuthMethod,
+	AuthProxySetHeaders,
+	AuthCacheKey,
+	AuthCacheDuration,
+	AuthKeepalive,
+	AuthSnippet,
+	EnableGlobalAuth,
 	ProxyBodySize,
 	ConfigurationSnippet,
 	EnableCORS,
@@ -91,13 +151,25 @@ var NginxAnnotations = []Annotation{
 	EnableOpentelemetry,
 	BackendProtocol,
 	GrpcBackend,
+	ProxySSLSecret,
+	ProxySSLVerify,
+	ProxySSLVerifyDepth,
+	ProxySSLName,
+	ProxySSLServerName,
+	ProxySSLProtocols,
 	ProxyBufferSize,
 	LimitConnections,
 	LimitRPS,
 	LimitRPM,
 	LimitBurstMultiplier,
+	ProxyConnectTimeout,
 	ProxyReadTimeout,
 	ProxySendTimeout,
+	ProxyNextUpstream,
+	ProxyNextUpstreamTries,
+	ProxyNextUpstreamTimeout,
+	CustomHTTPErrors,
+	DefaultBackend,
 	RewriteTarget,
 	AppRoot,
 	PermanentRedirect,
@@ -118,6 +190,48 @@ var NginxAnnotations = []Annotation{
 	HSTSPreload,
 	FromToWWWRedirect,
 	WhitelistSourceRange,
+	Canary,
+	CanaryWeight,
+	CanaryWeightTotal,
+	CanaryByHeader,
+	CanaryByHeaderValue,
+	CanaryByHeaderPattern,
+	CanaryByCookie,
+	Affinity,
+	AffinityMode,
+	SessionCookieName,
+	SessionCookiePath,
+	SessionCookieSameSite,
+	SessionCookieSecure,
+	SessionCookieMaxAge,
+	SessionCookieExpires,
+	SessionCookieChangeOnFailure,
+}
+
+// AffinityAnnotations are the session affinity annotations. Canary
+// Ingresses keep them instead of inheriting them from their primary.
+var AffinityAnnotations = []Annotation{
+	Affinity,
+	AffinityMode,
+	SessionCookieName,
+	SessionCookiePath,
+	SessionCookieSameSite,
+	SessionCookieSecure,
+	SessionCookieMaxAge,
+	SessionCookieExpires,
+	SessionCookieChangeOnFailure,
+}
+
+// CanaryAnnotations are the annotations a canary Ingress keeps; every other
+// annotation is inherited from its primary Ingress.
+var CanaryAnnotations = []Annotation{
+	Canary,
+	CanaryWeight,
+	CanaryWeightTotal,
+	CanaryByHeader,
+	CanaryByHeaderValue,
+	CanaryByHeaderPattern,
+	CanaryByCookie,
 }
 
 // CertManagerAnnotations contains cert-manager annotations that the converter
@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+)
+
+/* ---------------- PROXY SSL ---------------- */
+
+// ProxySSL handles the below annotations.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-secret"
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-verify"
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-verify-depth"
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-name"
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-server-name"
+//   - "nginx.ingress.kubernetes.io/proxy-ssl-protocols"
+//
+// nginx does not verify upstream certificates unless proxy-ssl-verify is on,
+// while Traefik always does, so TLS backends get a ServersTransport with
+// insecureSkipVerify unless verification was asked for.
+func ProxySSL(ctx configs.Context) {
+	ctx.Log.Debug("running converter ProxySSL")
+
+	annSecret := string(models.ProxySSLSecret)
+	annVerify := string(models.ProxySSLVerify)
+	annDepth := string(models.ProxySSLVerifyDepth)
+	annName := string(models.ProxySSLName)
+	annServerName := string(models.ProxySSLServerName)
+	annProtocols := string(models.ProxySSLProtocols)
+
+	related := []string{annVerify, annDepth, annName, annServerName, annProtocols}
+
+	ignore := func(msg string, anns ...string) {
+		for _, ann := range anns {
+			if _, ok := ctx.Annotations[ann]; ok {
+				ctx.ReportIgnored(ann, msg)
+			}
+		}
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(ctx.Annotations[string(models.BackendProtocol)])) {
+	case "HTTPS", "GRPCS":
+	default:
+		ignore("backend-protocol is not HTTPS or GRPCS, the backend is not reached over TLS",
+			append([]string{annSecret}, related...)...)
+
+		return
+	}
+
+	st := serversTransport(ctx)
+
+	secret := strings.TrimSpace(ctx.Annotations[annSecret])
+	if secret == "" {
+		// ingress-nginx drops the whole proxy-ssl configuration without a secret.
+		st.Spec.InsecureSkipVerify = true
+
+		ignore("proxy-ssl-secret is not set, ingress-nginx ignores the other proxy-ssl annotations", related...)
+		ctx.Result.Warnings = append(ctx.Result.Warnings,
+			"backend certificates are not verified, as in nginx; ServersTransport insecureSkipVerify is set")
+
+		return
+	}
+
+	secretName := secret
+	if namespace, name, ok := strings.Cut(secret, "/"); ok {
+		secretName = name
+
+		if namespace == ctx.Namespace {
+			ctx.ReportConverted(annSecret)
+		} else {
+			msg := fmt.Sprintf("proxy-ssl-secret %q is in another namespace, Traefik reads secret %q from namespace %s; copy it there",
+				secret, name, ctx.Namespace)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annSecret, msg)
+		}
+	} else {
+		ctx.ReportConverted(annSecret)
+	}
+
+	// The secret holds the client certificate in tls.crt and tls.key.
+	st.Spec.CertificatesSecrets = []string{secretName}
+
+	verify := strings.EqualFold(strings.TrimSpace(ctx.Annotations[annVerify]), "on")
+	if verify {
+		// And the CA verifying the backend in ca.crt.
+		st.Spec.RootCAsSecrets = []string{secretName}
+	} else {
+		st.Spec.InsecureSkipVerify = true
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings,
+			"backend certificates are not verified, as in nginx; ServersTransport insecureSkipVerify is set")
+	}
+
+	if _, ok := ctx.Annotations[annVerify]; ok {
+		ctx.ReportConverted(annVerify)
+	}
+
+	if _, ok := ctx.Annotations[annDepth]; ok {
+		if verify {
+			msg := "proxy-ssl-verify-depth has no Traefik equivalent, the whole backend certificate chain is verified"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportSkipped(annDepth, msg)
+		} else {
+			ctx.ReportIgnored(annDepth, "proxy-ssl-verify is not on")
+		}
+	}
+
+	serverNameOn := strings.EqualFold(strings.TrimSpace(ctx.Annotations[annServerName]), "on")
+
+	if name := strings.TrimSpace(ctx.Annotations[annName]); name != "" {
+		st.Spec.ServerName = name
+
+		if serverNameOn {
+			ctx.ReportConverted(annName)
+		} else {
+			msg := "Traefik also sends proxy-ssl-name as SNI, which nginx only does with proxy-ssl-server-name on"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annName, msg)
+		}
+	}
+
+	if _, ok := ctx.Annotations[annServerName]; ok {
+		switch {
+		case !serverNameOn, st.Spec.ServerName != "":
+			ctx.ReportConverted(annServerName)
+		default:
+			msg := "proxy-ssl-server-name is on without proxy-ssl-name; nginx sends the upstream name as SNI " +
+				"while Traefik sends none to pod IPs, set serverName on the ServersTransport if the backend needs it"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annServerName, msg)
+		}
+	}
+
+	if protocols, ok := ctx.Annotations[annProtocols]; ok {
+		if modernTLSOnly(protocols) {
+			ctx.ReportIgnored(annProtocols, "Traefik only negotiates TLSv1.2 and TLSv1.3 with backends")
+		} else {
+			msg := fmt.Sprintf("proxy-ssl-protocols %q cannot be converted, Traefik only negotiates TLSv1.2 and TLSv1.3 with backends",
+				protocols)
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportSkipped(annProtocols, msg)
+		}
+	}
+}
+
+// modernTLSOnly reports whether the nginx protocol list only names protocols
+// Traefik negotiates with backends.
+func modernTLSOnly(protocols string) bool {
+	for _, protocol := range strings.Fields(protocols) {
+		if protocol != "TLSv1.2" && protocol != "TLSv1.3" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/converters/models"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+/* ---------------- PROXY TIMEOUTS ---------------- */
+
+// ProxyTimeouts handles the below annotations.
+// Annotations:
+//   - "nginx.ingress.kubernetes.io/proxy-connect-timeout"
+//   - "nginx.ingress.kubernetes.io/proxy-read-timeout"
+//   - "nginx.ingress.kubernetes.io/proxy-send-timeout"
+//
+// The timeouts are set on the forwardingTimeouts of the Ingress
+// ServersTransport, which is shared with ProxySSL.
+func ProxyTimeouts(ctx configs.Context) {
+	ctx.Log.Debug("running converter ProxyTimeouts")
+
+	annConnect := string(models.ProxyConnectTimeout)
+	annRead := string(models.ProxyReadTimeout)
+	annSend := string(models.ProxySendTimeout)
+
+	connect, okConnect := timeoutSeconds(ctx, annConnect)
+	read, okRead := timeoutSeconds(ctx, annRead)
+
+	if okConnect || okRead {
+		st := serversTransport(ctx)
+		if st.Spec.ForwardingTimeouts == nil {
+			st.Spec.ForwardingTimeouts = &traefik.ForwardingTimeouts{}
+		}
+
+		if okConnect {
+			st.Spec.ForwardingTimeouts.DialTimeout = connect
+
+			ctx.ReportConverted(annConnect)
+		}
+
+		if okRead {
+			st.Spec.ForwardingTimeouts.ResponseHeaderTimeout = read
+
+			msg := "proxy-read-timeout converted to ServersTransport forwardingTimeouts.responseHeaderTimeout; " +
+				"nginx limits the time between two reads of the whole response, " +
+				"Traefik only limits the wait for the response headers and never times out a slow body"
+
+			ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+			ctx.ReportWarning(annRead, msg)
+		}
+	}
+
+	if _, ok := ctx.Annotations[annSend]; ok {
+		msg := "proxy-send-timeout has no Traefik equivalent: nginx limits the time between two writes " +
+			"of the request to the backend, Traefik has no timeout while sending the request"
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(annSend, msg)
+	}
+}
+
+// timeoutSeconds parses a timeout annotation, given in seconds as in nginx.
+// Invalid values are reported as skipped.
+func timeoutSeconds(ctx configs.Context, ann string) (*intstr.IntOrString, bool) {
+	val, ok := ctx.Annotations[ann]
+	if !ok {
+		return nil, false
+	}
+
+	seconds, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || seconds < 0 {
+		msg := fmt.Sprintf("invalid %s %q, expected seconds", strings.TrimPrefix(ann, models.Prefix), val)
+
+		ctx.Result.Warnings = append(ctx.Result.Warnings, msg)
+		ctx.ReportSkipped(ann, msg)
+
+		return nil, false
+	}
+
+	timeout := intstr.FromString(strconv.Itoa(seconds) + "s")
+
+	return &timeout, true
+}
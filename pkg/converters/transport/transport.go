@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Name returns the name of the ServersTransport generated for the Ingress,
+// or an empty string when none was generated.
+func Name(ctx configs.Context) string {
+	name := transportName(ctx)
+
+	for _, st := range ctx.Result.ServersTransports {
+		if st.Name == name {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// serversTransport returns the ServersTransport of the Ingress, creating it
+// on first use so that every converter extends the same object.
+func serversTransport(ctx configs.Context) *traefik.ServersTransport {
+	name := transportName(ctx)
+
+	for _, st := range ctx.Result.ServersTransports {
+		if st.Name == name {
+			return st
+		}
+	}
+
+	st := &traefik.ServersTransport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: traefik.SchemeGroupVersion.String(),
+			Kind:       "ServersTransport",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ctx.Namespace,
+		},
+	}
+
+	ctx.Result.ServersTransports = append(ctx.Result.ServersTransports, st)
+
+	return st
+}
+
+func transportName(ctx configs.Context) string {
+	return ctx.IngressName + "-transport"
+}
@@ -0,0 +1,133 @@
+// Package filter selects which source Ingresses are handed to the converters.
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// NginxController is the spec.controller value of IngressClasses served by ingress-nginx.
+	NginxController = "k8s.io/ingress-nginx"
+
+	// LegacyClassAnnotation selects the ingress class before spec.ingressClassName existed.
+	LegacyClassAnnotation = "kubernetes.io/ingress.class"
+
+	// DefaultClassAnnotation marks the IngressClass used by Ingresses that set no class.
+	DefaultClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+)
+
+// DefaultClasses are the --ingress-class values used when none are given:
+// the conventional class name and the ingress-nginx controller.
+var DefaultClasses = []string{"nginx", NginxController}
+
+// ClassFilter decides whether an Ingress is served by one of the selected
+// ingress classes. A selected value matches either the name of the class or
+// the spec.controller of the IngressClass object with that name.
+type ClassFilter struct {
+	selected         map[string]struct{}
+	controllers      map[string]string
+	defaultClass     string
+	includeClassless bool
+}
+
+// NewClassFilter returns a ClassFilter for the selected class names or
+// controllers, resolved against the known IngressClass objects.
+// When includeClassless is set, Ingresses without any class are accepted
+// even if no default IngressClass exists, mirroring ingress-nginx's
+// --watch-ingress-without-class flag.
+func NewClassFilter(selected []string, ingressClasses []netv1.IngressClass, includeClassless bool) *ClassFilter {
+	filter := &ClassFilter{
+		selected:         make(map[string]struct{}, len(selected)),
+		controllers:      make(map[string]string, len(ingressClasses)),
+		includeClassless: includeClassless,
+	}
+
+	for _, class := range selected {
+		if class = strings.TrimSpace(class); class != "" {
+			filter.selected[class] = struct{}{}
+		}
+	}
+
+	for _, class := range ingressClasses {
+		filter.controllers[class.Name] = class.Spec.Controller
+	}
+
+	filter.defaultClass = DefaultClass(ingressClasses)
+
+	return filter
+}
+
+// DefaultClass returns the name of the IngressClass marked as default, used
+// by Ingresses that set no class, or "" when none is.
+func DefaultClass(ingressClasses []netv1.IngressClass) string {
+	defaultClass := ""
+
+	for _, class := range ingressClasses {
+		if strings.EqualFold(class.Annotations[DefaultClassAnnotation], "true") {
+			defaultClass = class.Name
+		}
+	}
+
+	return defaultClass
+}
+
+// Match reports whether the Ingress belongs to a selected class. When it
+// does not, the returned reason explains why so it can be reported.
+func (f *ClassFilter) Match(ingress *netv1.Ingress) (bool, string) {
+	class, source := IngressClass(ingress)
+
+	if class == "" {
+		switch {
+		case f.defaultClass != "":
+			class, source = f.defaultClass, "default IngressClass"
+		case f.includeClassless:
+			return true, ""
+		default:
+			return false, "Ingress sets no ingress class and no default IngressClass is defined"
+		}
+	}
+
+	if f.matchClass(class) {
+		return true, ""
+	}
+
+	reason := fmt.Sprintf("ingress class %q (from %s) is not selected", class, source)
+	if controller := f.controllers[class]; controller != "" {
+		reason = fmt.Sprintf("ingress class %q (from %s, controller %q) is not selected", class, source, controller)
+	}
+
+	return false, reason
+}
+
+func (f *ClassFilter) matchClass(class string) bool {
+	if _, ok := f.selected[class]; ok {
+		return true
+	}
+
+	controller, ok := f.controllers[class]
+	if !ok {
+		return false
+	}
+
+	_, ok = f.selected[controller]
+
+	return ok
+}
+
+// IngressClass returns the class an Ingress explicitly asks for together
+// with where it was found. spec.ingressClassName wins over the legacy
+// annotation, as in ingress-nginx.
+func IngressClass(ingress *netv1.Ingress) (class, source string) {
+	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
+		return *ingress.Spec.IngressClassName, "spec.ingressClassName"
+	}
+
+	if class = strings.TrimSpace(ingress.Annotations[LegacyClassAnnotation]); class != "" {
+		return class, LegacyClassAnnotation + " annotation"
+	}
+
+	return "", ""
+}
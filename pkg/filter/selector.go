@@ -0,0 +1,120 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Selector scopes the Ingresses to convert by namespace, labels, fields and
+// name patterns. It applies the same rules to Ingresses listed from the
+// cluster and to Ingresses read from manifest files.
+type Selector struct {
+	namespaces map[string]struct{}
+	labels     labels.Selector
+	fields     fields.Selector
+	include    []*regexp.Regexp
+	exclude    []*regexp.Regexp
+}
+
+// NewSelector parses the selection flags.
+//   - namespaces limits Ingresses to the given namespaces, all when empty.
+//   - labelSelector and fieldSelector use the kubectl syntax; the only
+//     Ingress fields are metadata.name and metadata.namespace.
+//   - include and exclude are regular expressions matched against both the
+//     Ingress name and "namespace/name". An Ingress is kept when it matches
+//     any include pattern (or none are given) and no exclude pattern.
+func NewSelector(namespaces []string, labelSelector, fieldSelector string, include, exclude []string) (*Selector, error) {
+	selector := &Selector{
+		labels: labels.Everything(),
+		fields: fields.Everything(),
+	}
+
+	if len(namespaces) != 0 {
+		selector.namespaces = make(map[string]struct{}, len(namespaces))
+
+		for _, namespace := range namespaces {
+			selector.namespaces[namespace] = struct{}{}
+		}
+	}
+
+	var err error
+
+	if labelSelector != "" {
+		if selector.labels, err = labels.Parse(labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", labelSelector, err)
+		}
+	}
+
+	if fieldSelector != "" {
+		if selector.fields, err = fields.ParseSelector(fieldSelector); err != nil {
+			return nil, fmt.Errorf("invalid field selector %q: %w", fieldSelector, err)
+		}
+	}
+
+	if selector.include, err = compilePatterns(include); err != nil {
+		return nil, err
+	}
+
+	if selector.exclude, err = compilePatterns(exclude); err != nil {
+		return nil, err
+	}
+
+	return selector, nil
+}
+
+// Match reports whether the Ingress is in scope.
+func (s *Selector) Match(ingress *netv1.Ingress) bool {
+	if s.namespaces != nil {
+		if _, ok := s.namespaces[ingress.Namespace]; !ok {
+			return false
+		}
+	}
+
+	if !s.labels.Matches(labels.Set(ingress.Labels)) {
+		return false
+	}
+
+	if !s.fields.Matches(fields.Set{
+		"metadata.name":      ingress.Name,
+		"metadata.namespace": ingress.Namespace,
+	}) {
+		return false
+	}
+
+	if len(s.include) != 0 && !matchesAny(s.include, ingress) {
+		return false
+	}
+
+	return !matchesAny(s.exclude, ingress)
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, ingress *netv1.Ingress) bool {
+	qualified := ingress.Namespace + "/" + ingress.Name
+
+	for _, re := range patterns {
+		if re.MatchString(ingress.Name) || re.MatchString(qualified) {
+			return true
+		}
+	}
+
+	return false
+}
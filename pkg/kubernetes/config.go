@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -14,9 +15,9 @@ import (
 
 // Config holds values required to initialise the kubernetes client.
 type Config struct {
-	NameSpace     string `json:"name_space,omitempty" yaml:"name_space,omitempty"`
-	Context       string `json:"context,omitempty"    yaml:"context,omitempty"`
-	All           bool   `json:"all,omitempty"        yaml:"all,omitempty"`
+	NameSpaces    []string `json:"name_spaces,omitempty" yaml:"name_spaces,omitempty"`
+	Context       string   `json:"context,omitempty"     yaml:"context,omitempty"`
+	All           bool     `json:"all,omitempty"         yaml:"all,omitempty"`
 	clientSet     *kubernetes.Clientset
 	dynamicClient dynamic.Interface
 	logger        *slog.Logger
@@ -67,13 +68,41 @@ func (cfg *Config) SetKubeClient() error {
 	return nil
 }
 
-// SetKubeNameSpace sets namespace to the initialised client.
+// SetKubeNameSpace sets namespaces to the initialised client.
+// Duplicates are dropped, and --all replaces the list with every namespace.
 func (cfg *Config) SetKubeNameSpace() {
 	if cfg.All {
-		cfg.NameSpace = ""
+		cfg.NameSpaces = []string{metav1.NamespaceAll}
 	}
 
-	cfg.logger.Debug("using namespace", slog.Any("namespace", cfg.NameSpace))
+	namespaces := make([]string, 0, len(cfg.NameSpaces))
+	seen := make(map[string]struct{}, len(cfg.NameSpaces))
+
+	for _, namespace := range cfg.NameSpaces {
+		namespace = strings.TrimSpace(namespace)
+		if _, ok := seen[namespace]; ok {
+			continue
+		}
+
+		seen[namespace] = struct{}{}
+		namespaces = append(namespaces, namespace)
+	}
+
+	cfg.NameSpaces = namespaces
+
+	cfg.logger.Debug("using namespaces", slog.Any("namespaces", cfg.NameSpaces))
+}
+
+// DefaultNameSpace returns the namespace given to objects read from
+// manifests without one: the first selected namespace, "default" otherwise.
+func (cfg *Config) DefaultNameSpace() string {
+	for _, namespace := range cfg.NameSpaces {
+		if namespace != metav1.NamespaceAll {
+			return namespace
+		}
+	}
+
+	return metav1.NamespaceDefault
 }
 
 // GetKubeClient returns the configured kube client.
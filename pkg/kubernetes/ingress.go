@@ -7,9 +7,25 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// ListAllIngresses list all the ingresses from the specified namespace
+// ListAllIngresses list all the ingresses from the specified namespaces
+// matching the label and field selectors.
 // This paginates and returns all available ingresses from the cluster.
-func (cfg *Config) ListAllIngresses() ([]netv1.Ingress, error) {
+func (cfg *Config) ListAllIngresses(labelSelector, fieldSelector string) ([]netv1.Ingress, error) {
+	ingresses := make([]netv1.Ingress, 0)
+
+	for _, namespace := range cfg.NameSpaces {
+		items, err := cfg.listIngresses(namespace, labelSelector, fieldSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		ingresses = append(ingresses, items...)
+	}
+
+	return ingresses, nil
+}
+
+func (cfg *Config) listIngresses(namespace, labelSelector, fieldSelector string) ([]netv1.Ingress, error) {
 	const pageSize int64 = 100
 
 	var continueToken string
@@ -18,11 +34,13 @@ func (cfg *Config) ListAllIngresses() ([]netv1.Ingress, error) {
 
 	for {
 		opts := metav1.ListOptions{
-			Limit:    pageSize,
-			Continue: continueToken,
+			LabelSelector: labelSelector,
+			FieldSelector: fieldSelector,
+			Limit:         pageSize,
+			Continue:      continueToken,
 		}
 
-		list, err := cfg.clientSet.NetworkingV1().Ingresses(cfg.NameSpace).List(context.TODO(), opts)
+		list, err := cfg.clientSet.NetworkingV1().Ingresses(namespace).List(context.TODO(), opts)
 		if err != nil {
 			return nil, err
 		}
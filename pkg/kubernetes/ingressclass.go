@@ -0,0 +1,19 @@
+package kubernetes
+
+import (
+	"context"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListIngressClasses lists the cluster-scoped IngressClass objects, used to
+// resolve which controller serves each Ingress.
+func (cfg *Config) ListIngressClasses() ([]netv1.IngressClass, error) {
+	list, err := cfg.clientSet.NetworkingV1().IngressClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
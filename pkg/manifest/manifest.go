@@ -0,0 +1,289 @@
+// Package manifest reads Kubernetes objects from rendered manifests so the
+// converter can run without access to a live cluster.
+//
+// Inputs may be single files, directories (walked recursively for .yaml,
+// .yml and .json files) or glob patterns. Every file may contain several
+// YAML documents separated by "---", a JSON object, or a "kind: List"
+// wrapping several items.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Document is a single Kubernetes object decoded from a manifest.
+type Document struct {
+	// Source is the file the object was read from ("-" for stdin).
+	Source string
+
+	// Object is the decoded object.
+	Object *unstructured.Unstructured
+
+	// Raw holds the document exactly as it was read, so that objects which
+	// are not converted can be passed through untouched. It is empty for
+	// items extracted from a "kind: List".
+	Raw []byte
+}
+
+// ReadPaths expands the given files, directories and glob patterns and
+// decodes every object found in them. Files are read in lexical order so
+// that the output is deterministic.
+func ReadPaths(paths []string) ([]Document, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, 0)
+
+	for _, file := range files {
+		fileDocs, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, fileDocs...)
+	}
+
+	return docs, nil
+}
+
+// Decode reads a stream of YAML or JSON documents from reader.
+// Empty documents are dropped and "kind: List" objects are flattened into
+// their items.
+func Decode(reader io.Reader, source string) ([]Document, error) {
+	yamlReader := utilyaml.NewYAMLReader(bufio.NewReader(reader))
+	docs := make([]Document, 0)
+
+	for {
+		raw, err := yamlReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("reading manifest %q: %w", source, err)
+		}
+
+		data, err := yaml.YAMLToJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decoding manifest %q: %w", source, err)
+		}
+
+		// Documents holding only comments or whitespace decode to null.
+		if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err = obj.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("decoding manifest %q: %w", source, err)
+		}
+
+		if !obj.IsList() {
+			docs = append(docs, Document{Source: source, Object: obj, Raw: raw})
+
+			continue
+		}
+
+		if err = obj.EachListItem(func(item runtime.Object) error {
+			if u, ok := item.(*unstructured.Unstructured); ok {
+				docs = append(docs, Document{Source: source, Object: u})
+			}
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("reading list items from manifest %q: %w", source, err)
+		}
+	}
+
+	return docs, nil
+}
+
+// IsIngress reports whether the object is a networking.k8s.io/v1 Ingress.
+func IsIngress(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "Ingress" && obj.GetAPIVersion() == netv1.SchemeGroupVersion.String()
+}
+
+// Ingresses picks the networking.k8s.io/v1 Ingress objects out of docs.
+// Objects of any other kind are returned as skipped resources so they can be
+// listed in the global report. Ingresses without a namespace are placed in
+// defaultNamespace, mirroring what "kubectl apply -f" would do.
+func Ingresses(docs []Document, defaultNamespace string) ([]netv1.Ingress, []configs.SkippedResource, error) {
+	ingresses := make([]netv1.Ingress, 0)
+	skipped := make([]configs.SkippedResource, 0)
+
+	for _, doc := range docs {
+		obj := doc.Object
+
+		if !IsIngress(obj) {
+			reason := fmt.Sprintf("%s %s is not a networking.k8s.io/v1 Ingress", obj.GetAPIVersion(), obj.GetKind())
+			if obj.GetKind() == "Ingress" {
+				reason = fmt.Sprintf("Ingress apiVersion %q is not supported, only %s", obj.GetAPIVersion(), netv1.SchemeGroupVersion.String())
+			}
+
+			skipped = append(skipped, configs.SkippedResource{
+				Kind:      obj.GetKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Source:    doc.Source,
+				Reason:    reason,
+			})
+
+			continue
+		}
+
+		var ingress netv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ingress); err != nil {
+			return nil, nil, fmt.Errorf("decoding Ingress %q from %q: %w", obj.GetName(), doc.Source, err)
+		}
+
+		if ingress.Namespace == "" {
+			ingress.Namespace = defaultNamespace
+		}
+
+		ingresses = append(ingresses, ingress)
+	}
+
+	return ingresses, skipped, nil
+}
+
+// IngressClasses returns the networking.k8s.io/v1 IngressClass objects found
+// in docs, used to resolve ingress classes without cluster access.
+func IngressClasses(docs []Document) ([]netv1.IngressClass, error) {
+	classes := make([]netv1.IngressClass, 0)
+
+	for _, doc := range docs {
+		obj := doc.Object
+		if obj.GetKind() != "IngressClass" || obj.GetAPIVersion() != netv1.SchemeGroupVersion.String() {
+			continue
+		}
+
+		var class netv1.IngressClass
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &class); err != nil {
+			return nil, fmt.Errorf("decoding IngressClass %q from %q: %w", obj.GetName(), doc.Source, err)
+		}
+
+		classes = append(classes, class)
+	}
+
+	return classes, nil
+}
+
+// ConfigMap returns the v1 ConfigMap namespace/name found in docs, or nil
+// when there is none. An empty name matches the first ConfigMap, which is
+// how a file holding only the controller ConfigMap is read.
+func ConfigMap(docs []Document, namespace, name string) (*corev1.ConfigMap, error) {
+	for _, doc := range docs {
+		obj := doc.Object
+		if obj.GetKind() != "ConfigMap" || obj.GetAPIVersion() != corev1.SchemeGroupVersion.String() {
+			continue
+		}
+
+		if name != "" && (obj.GetName() != name || obj.GetNamespace() != namespace) {
+			continue
+		}
+
+		var configMap corev1.ConfigMap
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &configMap); err != nil {
+			return nil, fmt.Errorf("decoding ConfigMap %q from %q: %w", obj.GetName(), doc.Source, err)
+		}
+
+		return &configMap, nil
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+func readFile(path string) ([]Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(bytes.NewReader(data), path)
+}
+
+// expandPaths resolves globs and walks directories, returning a sorted,
+// de-duplicated list of manifest files.
+func expandPaths(paths []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	files := make([]string, 0)
+
+	add := func(file string) {
+		if _, ok := seen[file]; ok {
+			return
+		}
+
+		seen[file] = struct{}{}
+		files = append(files, file)
+	}
+
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest path pattern %q: %w", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("manifest path %q did not match any file", pattern)
+		}
+
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if !info.IsDir() {
+				add(match)
+
+				continue
+			}
+
+			if err = filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !entry.IsDir() && isManifestFile(path) {
+					add(path)
+				}
+
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func isManifestFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
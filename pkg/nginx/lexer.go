@@ -0,0 +1,232 @@
+package nginx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Terminators of the words read by lexer.next.
+const (
+	endEOF        byte = 0
+	endDirective  byte = ';'
+	endBlockStart byte = '{'
+	endBlockEnd   byte = '}'
+)
+
+// word is a directive name or parameter with the line it starts on.
+type word struct {
+	value string
+	line  int
+}
+
+// lexer splits a configuration into words following ngx_conf_read_token:
+//   - words are separated by spaces, tabs and newlines, and end at ";" or "{";
+//   - a "#" at the start of a word comments out the rest of the line;
+//   - single or double quotes at the start of a word enclose it, and must be
+//     followed by a separator, ";", "{" or ")";
+//   - a backslash escapes the next character, and "\t", "\r" and "\n" stand
+//     for the control characters;
+//   - braces right after a "$" are part of the variable, as in "${name}".
+type lexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+// next reads the words up to the next ";", "{", "}" or the end of the
+// configuration, and returns them with the terminator found.
+//
+//nolint:gocognit,gocyclo,cyclop,funlen
+func (l *lexer) next() ([]word, byte, error) {
+	var (
+		words                               []word
+		start, startLine                    int
+		needSpace, sQuoted, dQuoted, quoted bool
+		sharpComment, variable              bool
+		lastSpace                           = true
+	)
+
+	for {
+		if l.pos >= len(l.src) {
+			switch {
+			case sQuoted || dQuoted:
+				return nil, endEOF, &SyntaxError{Line: startLine, Message: "quoted string is not closed"}
+			case len(words) != 0 || !lastSpace:
+				return nil, endEOF, &SyntaxError{Line: l.line, Message: `unexpected end of snippet, expecting ";" or "}"`}
+			}
+
+			return nil, endEOF, nil
+		}
+
+		char := l.src[l.pos]
+		l.pos++
+
+		if char == '\n' {
+			l.line++
+
+			if sharpComment {
+				sharpComment = false
+			}
+		}
+
+		if sharpComment {
+			continue
+		}
+
+		if quoted {
+			quoted = false
+
+			continue
+		}
+
+		if needSpace {
+			switch {
+			case isSpace(char):
+				lastSpace = true
+				needSpace = false
+
+				continue
+			case char == endDirective, char == endBlockStart:
+				return words, char, nil
+			case char == ')':
+				lastSpace = true
+				needSpace = false
+			default:
+				return nil, endEOF, l.unexpected(char)
+			}
+		}
+
+		if lastSpace {
+			start = l.pos - 1
+			startLine = l.line
+
+			if isSpace(char) {
+				continue
+			}
+
+			switch char {
+			case endDirective, endBlockStart:
+				if len(words) == 0 {
+					return nil, endEOF, l.unexpected(char)
+				}
+
+				return words, char, nil
+			case endBlockEnd:
+				if len(words) != 0 {
+					return nil, endEOF, l.unexpected(char)
+				}
+
+				return nil, char, nil
+			case '#':
+				sharpComment = true
+
+				continue
+			case '\\':
+				quoted = true
+			case '"':
+				start++
+				dQuoted = true
+			case '\'':
+				start++
+				sQuoted = true
+			case '$':
+				variable = true
+			}
+
+			lastSpace = false
+
+			continue
+		}
+
+		if char == '{' && variable {
+			continue
+		}
+
+		variable = false
+
+		switch {
+		case char == '\\':
+			quoted = true
+
+			continue
+		case char == '$':
+			variable = true
+
+			continue
+		case dQuoted:
+			if char != '"' {
+				continue
+			}
+
+			dQuoted = false
+			needSpace = true
+		case sQuoted:
+			if char != '\'' {
+				continue
+			}
+
+			sQuoted = false
+			needSpace = true
+		case isSpace(char), char == endDirective, char == endBlockStart:
+			lastSpace = true
+		default:
+			continue
+		}
+
+		words = append(words, word{value: unescape(l.src[start : l.pos-1]), line: startLine})
+
+		if char == endDirective || char == endBlockStart {
+			return words, char, nil
+		}
+	}
+}
+
+func (l *lexer) unexpected(char byte) error {
+	return &SyntaxError{Line: l.line, Message: fmt.Sprintf("unexpected %q", string(char))}
+}
+
+// unescape resolves the escapes of a word as nginx does: "\"", "\'" and
+// "\\" stand for the escaped character, "\t", "\r" and "\n" for the control
+// characters, and other backslashes are kept.
+func unescape(raw string) string {
+	if !strings.Contains(raw, `\`) {
+		return raw
+	}
+
+	var out strings.Builder
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			out.WriteByte(raw[i])
+
+			continue
+		}
+
+		switch raw[i+1] {
+		case '"', '\'', '\\':
+			out.WriteByte(raw[i+1])
+		case 't':
+			out.WriteByte('\t')
+		case 'r':
+			out.WriteByte('\r')
+		case 'n':
+			out.WriteByte('\n')
+		default:
+			out.WriteByte(raw[i])
+
+			continue
+		}
+
+		i++
+	}
+
+	return out.String()
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\r' || char == '\n'
+}
@@ -0,0 +1,137 @@
+// Package nginx parses the NGINX configuration found in ingress-nginx snippet
+// annotations into directives, so the converters can inspect them instead of
+// matching raw text.
+//
+// The parser follows the syntax rules of nginx itself: several directives may
+// share a line, one directive may span several lines, "#" starts a comment,
+// quoted parameters may contain spaces, semicolons and braces, and block
+// directives such as "if" or "location" nest.
+package nginx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Directive is a simple or block directive of an NGINX configuration.
+type Directive struct {
+	// Name is the directive name, for example "add_header".
+	Name string
+
+	// Args are the parameters of the directive, with the quotes removed and
+	// the escapes resolved.
+	Args []string
+
+	// Line is the line of the snippet the directive starts on, counted from 1.
+	Line int
+
+	// Block holds the directives between the braces of a block directive.
+	// It is nil for simple directives.
+	Block []*Directive
+}
+
+// SyntaxError reports a configuration nginx itself would reject.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse parses an NGINX configuration snippet. It returns a *SyntaxError
+// when the snippet is not valid.
+func Parse(src string) ([]*Directive, error) {
+	return parseBlock(newLexer(src), nil)
+}
+
+// parseBlock parses the directives up to the "}" closing parent, or up to
+// the end of the snippet when parent is nil.
+func parseBlock(lex *lexer, parent *Directive) ([]*Directive, error) {
+	directives := make([]*Directive, 0)
+
+	for {
+		words, end, err := lex.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch end {
+		case endEOF:
+			if parent != nil {
+				return nil, &SyntaxError{Line: parent.Line, Message: fmt.Sprintf("%q block is not closed", parent.Name)}
+			}
+
+			return directives, nil
+		case endBlockEnd:
+			if parent == nil {
+				return nil, &SyntaxError{Line: lex.line, Message: `unexpected "}"`}
+			}
+
+			return directives, nil
+		}
+
+		directive := &Directive{
+			Name: words[0].value,
+			Args: make([]string, 0, len(words)-1),
+			Line: words[0].line,
+		}
+
+		for _, arg := range words[1:] {
+			directive.Args = append(directive.Args, arg.value)
+		}
+
+		if end == endBlockStart {
+			if directive.Block, err = parseBlock(lex, directive); err != nil {
+				return nil, err
+			}
+		}
+
+		directives = append(directives, directive)
+	}
+}
+
+// IfCondition returns the condition of an "if" directive without its
+// parentheses, for example ["$request_method", "=", "OPTIONS"] for
+// if ($request_method = OPTIONS).
+func IfCondition(directive *Directive) ([]string, error) {
+	invalid := &SyntaxError{Line: directive.Line, Message: "invalid condition of if"}
+
+	if directive.Name != "if" || len(directive.Args) == 0 || !strings.HasPrefix(directive.Args[0], "(") {
+		return nil, invalid
+	}
+
+	condition := append([]string(nil), directive.Args...)
+
+	if condition[0] == "(" {
+		condition = condition[1:]
+	} else {
+		condition[0] = condition[0][1:]
+	}
+
+	last := len(condition) - 1
+
+	switch {
+	case last < 0 || !strings.HasSuffix(condition[last], ")"):
+		return nil, invalid
+	case condition[last] == ")":
+		condition = condition[:last]
+	default:
+		condition[last] = strings.TrimSuffix(condition[last], ")")
+	}
+
+	if len(condition) == 0 {
+		return nil, invalid
+	}
+
+	return condition, nil
+}
+
+// Walk calls fn for every directive, descending into the blocks in order.
+func Walk(directives []*Directive, fn func(*Directive)) {
+	for _, directive := range directives {
+		fn(directive)
+		Walk(directive.Block, fn)
+	}
+}
@@ -0,0 +1,225 @@
+package nginx
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []*Directive
+	}{
+		{
+			name: "empty snippet",
+			src:  " \n\t",
+			want: []*Directive{},
+		},
+		{
+			name: "directives sharing a line and spanning lines",
+			src:  "a b; c\n  d\n  e;",
+			want: []*Directive{
+				{Name: "a", Args: []string{"b"}, Line: 1},
+				{Name: "c", Args: []string{"d", "e"}, Line: 1},
+			},
+		},
+		{
+			name: "quoted parameters with escaped quotes",
+			src:  `more_set_headers "X-Q: a \"quoted\" b" 'Y: c\'d';`,
+			want: []*Directive{
+				{Name: "more_set_headers", Args: []string{`X-Q: a "quoted" b`, "Y: c'd"}, Line: 1},
+			},
+		},
+		{
+			name: "quoted parameters keep semicolons and braces",
+			src:  `add_header X "a; {b}";`,
+			want: []*Directive{
+				{Name: "add_header", Args: []string{"X", "a; {b}"}, Line: 1},
+			},
+		},
+		{
+			name: "control escapes are resolved and other backslashes kept",
+			src:  `proxy_set_header X a\tb\qc;`,
+			want: []*Directive{
+				{Name: "proxy_set_header", Args: []string{"X", "a\tb\\qc"}, Line: 1},
+			},
+		},
+		{
+			name: "braces of a variable are part of the word",
+			src:  `set $a Z${var}x;`,
+			want: []*Directive{
+				{Name: "set", Args: []string{"$a", "Z${var}x"}, Line: 1},
+			},
+		},
+		{
+			name: "comments at the start of a word only",
+			src:  "# comment; {\nadd_header X foo#bar; # trailing\n",
+			want: []*Directive{
+				{Name: "add_header", Args: []string{"X", "foo#bar"}, Line: 2},
+			},
+		},
+		{
+			name: "quote followed by a closing parenthesis",
+			src:  `if ($request_method = 'OPTIONS') { return 204; }`,
+			want: []*Directive{
+				{
+					Name: "if", Args: []string{"($request_method", "=", "OPTIONS", ")"}, Line: 1,
+					Block: []*Directive{{Name: "return", Args: []string{"204"}, Line: 1}},
+				},
+			},
+		},
+		{
+			name: "nested blocks and line numbers",
+			src:  "a b;\n\nlocation / {\n  if ($x) {\n  }\n  d e;\n}\n",
+			want: []*Directive{
+				{Name: "a", Args: []string{"b"}, Line: 1},
+				{
+					Name: "location", Args: []string{"/"}, Line: 3,
+					Block: []*Directive{
+						{Name: "if", Args: []string{"($x)"}, Line: 4, Block: []*Directive{}},
+						{Name: "d", Args: []string{"e"}, Line: 6},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %s, want %s", dump(got), dump(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want SyntaxError
+	}{
+		{
+			name: "unclosed block",
+			src:  "if ($a) {\n  add_header X y;\n",
+			want: SyntaxError{Line: 1, Message: `"if" block is not closed`},
+		},
+		{
+			name: "unclosed nested block",
+			src:  "location / {\n  if ($a) {\n}\n",
+			want: SyntaxError{Line: 1, Message: `"location" block is not closed`},
+		},
+		{
+			name: "unexpected closing brace",
+			src:  "a b;\n}",
+			want: SyntaxError{Line: 2, Message: `unexpected "}"`},
+		},
+		{
+			name: "unclosed quote",
+			src:  "a b;\nadd_header X \"abc;\n",
+			want: SyntaxError{Line: 2, Message: "quoted string is not closed"},
+		},
+		{
+			name: "missing semicolon",
+			src:  "add_header X y",
+			want: SyntaxError{Line: 1, Message: `unexpected end of snippet, expecting ";" or "}"`},
+		},
+		{
+			name: "text right after a quote",
+			src:  `add_header X "a"b;`,
+			want: SyntaxError{Line: 1, Message: `unexpected "b"`},
+		},
+		{
+			name: "semicolon without a directive",
+			src:  "a b;\n;",
+			want: SyntaxError{Line: 2, Message: `unexpected ";"`},
+		},
+		{
+			name: "closing brace inside a directive",
+			src:  "if ($a) { add_header X }",
+			want: SyntaxError{Line: 1, Message: `unexpected "}"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error = %v, want a *SyntaxError", err)
+			}
+
+			if *syntaxErr != tt.want {
+				t.Errorf("Parse() error = %q, want %q", syntaxErr.Error(), tt.want.Error())
+			}
+		})
+	}
+}
+
+func TestIfCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "separate parentheses", src: "if ( $a = b ) {}", want: []string{"$a", "=", "b"}},
+		{name: "attached parentheses", src: "if ($a = b) {}", want: []string{"$a", "=", "b"}},
+		{name: "quoted operand", src: "if ($request_method = 'OPTIONS') {}", want: []string{"$request_method", "=", "OPTIONS"}},
+		{name: "single variable", src: "if ($a) {}", want: []string{"$a"}},
+		{name: "empty condition", src: "if () {}", wantErr: true},
+		{name: "missing parentheses", src: "if $a {}", wantErr: true},
+		{name: "missing closing parenthesis", src: "if ($a = b {}", wantErr: true},
+		{name: "not an if", src: "location ($a) {}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directives, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got, err := IfCondition(directives[0])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IfCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IfCondition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	directives, err := Parse("a; b { c; d { e; } } f;")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var got []string
+
+	Walk(directives, func(directive *Directive) {
+		got = append(got, directive.Name)
+	})
+
+	if want := []string{"a", "b", "c", "d", "e", "f"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() visited %q, want %q", got, want)
+	}
+}
+
+func dump(directives []*Directive) string {
+	out, _ := json.Marshal(directives)
+
+	return string(out)
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// StdoutTarget is the --to-file value selecting stdout as output.
+const StdoutTarget = "-"
+
+// Sink receives the converted objects of every Ingress and persists them.
+type Sink interface {
+	// Write stores the result of converting the Ingress namespace/name.
+	Write(namespace, name string, res configs.Result) error
+
+	// Close flushes any pending output. It must be called once all Ingresses
+	// have been written.
+	Close() error
+}
+
+// NewSink returns the Sink matching the output options.
+//   - toFile "-" writes a single multi-document stream to stdout.
+//   - any other non-empty toFile writes a single multi-document file.
+//   - otherwise one directory per Ingress is created under outputDir.
+func NewSink(toFile, outputDir string) Sink {
+	switch toFile {
+	case "":
+		return &treeSink{root: outputDir}
+	case StdoutTarget:
+		return &streamSink{open: func() (io.WriteCloser, error) { return nopCloser{os.Stdout}, nil }}
+	default:
+		return &streamSink{open: func() (io.WriteCloser, error) { return createFile(toFile) }}
+	}
+}
+
+// treeSink writes ./<root>/<namespace>/<name>/{middlewares,ingressroutes,...}.yaml.
+type treeSink struct {
+	root string
+}
+
+func (s *treeSink) Write(namespace, name string, res configs.Result) error {
+	return WriteYAML(res, filepath.Join(s.root, namespace, name))
+}
+
+func (s *treeSink) Close() error {
+	return nil
+}
+
+// streamSink collects the objects of all Ingresses and writes them as one
+// multi-document YAML stream on Close, so that the document order does not
+// depend on the order in which Ingresses were converted.
+type streamSink struct {
+	open     func() (io.WriteCloser, error)
+	objects  []client.Object
+	warnings []string
+}
+
+func (s *streamSink) Write(namespace, name string, res configs.Result) error {
+	s.objects = append(s.objects, ResultObjects(res)...)
+
+	for _, warning := range res.Warnings {
+		s.warnings = append(s.warnings, fmt.Sprintf("%s/%s: %s", namespace, name, warning))
+	}
+
+	return nil
+}
+
+func (s *streamSink) Close() (err error) {
+	writeCloser, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	// The output is closed on every path, and its error kept with the first one.
+	defer func() {
+		err = errors.Join(err, writeCloser.Close())
+	}()
+
+	writer := bufio.NewWriter(writeCloser)
+	stream := NewStream(writer)
+
+	for _, obj := range sortObjects(s.objects) {
+		if err = stream.WriteObject(obj); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// Warnings returns the warnings of every Ingress written to a single-stream
+// sink, prefixed with the Ingress they belong to. Per-Ingress trees write
+// their warnings next to the manifests instead and return nothing here.
+func Warnings(sink Sink) []string {
+	if s, ok := sink.(*streamSink); ok {
+		return s.warnings
+	}
+
+	return nil
+}
+
+// kindOrder lists kinds in the order they are written: objects referenced
+// by an IngressRoute come before it so the stream can be applied as is.
+var kindOrder = map[string]int{
+	"Middleware":       0,
+	"TLSOption":        1,
+	"ServersTransport": 2,
+	"Certificate":      3,
+	"TraefikService":   4,
+	"IngressRoute":     5,
+}
+
+// sortObjects orders objects by kind, namespace and name, dropping exact
+// duplicates such as a Certificate shared by several Ingresses.
+func sortObjects(objs []client.Object) []client.Object {
+	sorted := make([]client.Object, 0, len(objs))
+	seen := make(map[string]struct{}, len(objs))
+
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			// Keep the object, the stream writer reports the error.
+			sorted = append(sorted, obj)
+
+			continue
+		}
+
+		// The serialised object includes kind, namespace and name.
+		key := string(data)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		sorted = append(sorted, obj)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left, right := sorted[i], sorted[j]
+
+		if kl, kr := kindRank(left), kindRank(right); kl != kr {
+			return kl < kr
+		}
+
+		if left.GetNamespace() != right.GetNamespace() {
+			return left.GetNamespace() < right.GetNamespace()
+		}
+
+		return left.GetName() < right.GetName()
+	})
+
+	return sorted
+}
+
+func objectKind(obj client.Object) string {
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+func kindRank(obj client.Object) int {
+	if rank, ok := kindOrder[objectKind(obj)]; ok {
+		return rank
+	}
+
+	return len(kindOrder)
+}
+
+func createFile(path string) (io.WriteCloser, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, dirPermission); err != nil {
+			return nil, err
+		}
+	}
+
+	return os.Create(path)
+}
+
+// nopCloser keeps stdout open once the stream has been written.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
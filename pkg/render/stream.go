@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
+	traefik "github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd/traefikio/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Stream writes Kubernetes objects to a writer as a multi-document YAML
+// stream, inserting "---" separators between documents.
+type Stream struct {
+	writer    io.Writer
+	documents int
+}
+
+// NewStream returns a Stream writing to writer.
+func NewStream(writer io.Writer) *Stream {
+	return &Stream{writer: writer}
+}
+
+// WriteObject marshals obj to YAML and appends it to the stream.
+func (s *Stream) WriteObject(obj client.Object) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if ingressRoute, ok := obj.(*traefik.IngressRoute); ok {
+		data, err = marshalIngressRoute(ingressRoute)
+	} else {
+		data, err = yaml.Marshal(obj)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.WriteRaw(data)
+}
+
+// WriteRaw appends an already serialised document to the stream unchanged.
+// Leading separators and surrounding blank lines are trimmed so that
+// documents passed through from another stream do not produce empty ones.
+func (s *Stream) WriteRaw(data []byte) error {
+	data = bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(data), []byte("---")))
+	if len(data) == 0 {
+		return nil
+	}
+
+	if s.documents > 0 {
+		if _, err := io.WriteString(s.writer, "\n---\n"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	s.documents++
+
+	return nil
+}
+
+// ResultObjects returns every object of a conversion result, ordered so
+// that the resources an IngressRoute refers to come before it.
+func ResultObjects(res configs.Result) []client.Object {
+	objs := make([]client.Object, 0,
+		len(res.Middlewares)+len(res.TLSOptions)+len(res.ServersTransports)+len(res.Certificates)+
+			len(res.TraefikServices)+len(res.IngressRoutes))
+
+	objs = append(objs, toClientObjects(res.Middlewares)...)
+	objs = append(objs, toClientObjects(res.TLSOptions)...)
+	objs = append(objs, toClientObjects(res.ServersTransports)...)
+	objs = append(objs, toClientObjects(res.Certificates)...)
+	objs = append(objs, toClientObjects(res.TraefikServices)...)
+	objs = append(objs, toClientObjects(res.IngressRoutes)...)
+
+	return objs
+}
+
+// marshalIngressRoute marshals an IngressRoute to YAML without the port of
+// its services of kind TraefikService, which have none.
+//
+// Traefik types the port of traefik.Service as an intstr.IntOrString. It is
+// a struct, which encoding/json writes even when empty despite omitempty,
+// so an unset port would be written as "port: 0". Only that field is
+// removed, everything else is written as yaml.Marshal would.
+func marshalIngressRoute(ingressRoute *traefik.IngressRoute) ([]byte, error) {
+	data, err := json.Marshal(ingressRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err = decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	spec, _ := object["spec"].(map[string]any)
+	routes, _ := spec["routes"].([]any)
+
+	for _, route := range routes {
+		route, _ := route.(map[string]any)
+		services, _ := route["services"].([]any)
+
+		for _, service := range services {
+			if service, ok := service.(map[string]any); ok && service["kind"] == "TraefikService" {
+				delete(service, "port")
+			}
+		}
+	}
+
+	return yaml.Marshal(object)
+}
@@ -5,6 +5,7 @@ package render
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -36,6 +37,9 @@ type Config struct {
 	// Table determines whether output should be rendered as tables (true)
 	// or as plain text (false).
 	Table bool `yaml:"table,omitempty" json:"table,omitempty"`
+
+	// Output is where reports are written, stdout when nil.
+	Output io.Writer `yaml:"-" json:"-"`
 }
 
 // statusLabel maps annotation statuses to human-readable labels for display.
@@ -79,22 +83,31 @@ func New() *Config {
 	return &Config{}
 }
 
+// writer returns the destination of the reports.
+func (cfg *Config) writer() io.Writer {
+	if cfg.Output == nil {
+		return os.Stdout
+	}
+
+	return cfg.Output
+}
+
 // ---------------- Separators ----------------
 
-func printSectionSeparator(title string) {
+func (cfg *Config) printSectionSeparator(title string) {
 	line := strings.Repeat("=", fixedStringLength)
-	fmt.Println(line)
-	fmt.Println(color.HiCyanString(title))
-	fmt.Println(line)
-	fmt.Println()
+	fmt.Fprintln(cfg.writer(), line)
+	fmt.Fprintln(cfg.writer(), color.HiCyanString(title))
+	fmt.Fprintln(cfg.writer(), line)
+	fmt.Fprintln(cfg.writer())
 }
 
-func printSubSectionSeparator(title string) {
+func (cfg *Config) printSubSectionSeparator(title string) {
 	line := strings.Repeat("-", fixedStringLength)
-	fmt.Println(line)
-	fmt.Println(title)
-	fmt.Println(line)
-	fmt.Println()
+	fmt.Fprintln(cfg.writer(), line)
+	fmt.Fprintln(cfg.writer(), title)
+	fmt.Fprintln(cfg.writer(), line)
+	fmt.Fprintln(cfg.writer())
 }
 
 // ---------------- Table Renderers ----------------
@@ -102,10 +115,10 @@ func printSubSectionSeparator(title string) {
 // printIngressReportTable renders a single Ingress report in table format,
 // including a detailed per-annotation table and a summary table.
 func (cfg *Config) printIngressReportTable(ingressReport configs.IngressReport) error {
-	printSectionSeparator(fmt.Sprintf("INGRESS: %s/%s", ingressReport.Namespace, ingressReport.Name))
+	cfg.printSectionSeparator(fmt.Sprintf("INGRESS: %s/%s", ingressReport.Namespace, ingressReport.Name))
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Annotation", "Status", "Message"})
+	table := tablewriter.NewWriter(cfg.writer())
+	table.Header([]string{"Annotation", "Status", "Message", "Source"})
 
 	rows := make([][]string, 0, len(ingressReport.Entries))
 
@@ -115,7 +128,12 @@ func (cfg *Config) printIngressReportTable(ingressReport configs.IngressReport)
 			msg = "-"
 		}
 
-		rows = append(rows, []string{entries.Name, statusLabelColored(entries.Status), msg})
+		source := entries.Source
+		if source == "" {
+			source = "Ingress"
+		}
+
+		rows = append(rows, []string{entries.Name, statusLabelColored(entries.Status), msg, source})
 	}
 
 	if err := table.Bulk(rows); err != nil {
@@ -127,22 +145,50 @@ func (cfg *Config) printIngressReportTable(ingressReport configs.IngressReport)
 	}
 
 	// Render per-Ingress summary table.
-	printSubSectionSeparator("SUMMARY")
+	cfg.printSubSectionSeparator("SUMMARY")
 
-	return renderSummaryTable(summarizeIngress(ingressReport))
+	return cfg.renderSummaryTable(summarizeIngress(ingressReport))
 }
 
 // printGlobalSummaryTable renders the global summary across all Ingresses
 // in table format.
 func (cfg *Config) printGlobalSummaryTable(globalReport configs.GlobalReport) error {
-	printSectionSeparator("GLOBAL SUMMARY")
+	cfg.printSectionSeparator("GLOBAL SUMMARY")
+
+	if err := cfg.renderSummaryTable(summarizeGlobal(globalReport)); err != nil {
+		return err
+	}
+
+	if len(globalReport.Skipped) == 0 {
+		return nil
+	}
+
+	cfg.printSubSectionSeparator("SKIPPED RESOURCES")
+
+	table := tablewriter.NewWriter(cfg.writer())
+	table.Header([]string{"Kind", "Resource", "Source", "Reason"})
+
+	rows := make([][]string, 0, len(globalReport.Skipped))
+
+	for _, skipped := range globalReport.Skipped {
+		source := skipped.Source
+		if source == "" {
+			source = "-"
+		}
+
+		rows = append(rows, []string{skipped.Kind, skippedResourceName(skipped), source, skipped.Reason})
+	}
 
-	return renderSummaryTable(summarizeGlobal(globalReport))
+	if err := table.Bulk(rows); err != nil {
+		return err
+	}
+
+	return table.Render()
 }
 
 // renderSummaryTable renders a generic summary table given a title and summary counts.
-func renderSummaryTable(summaryCounts SummaryCounts) error {
-	summary := tablewriter.NewWriter(os.Stdout)
+func (cfg *Config) renderSummaryTable(summaryCounts SummaryCounts) error {
+	summary := tablewriter.NewWriter(cfg.writer())
 	summary.Header([]string{"Metric", "Count"})
 
 	rows := [][]string{
@@ -164,23 +210,27 @@ func renderSummaryTable(summaryCounts SummaryCounts) error {
 
 // printIngressReport renders a single Ingress report in plain text format.
 func (cfg *Config) printIngressReport(ingressReport configs.IngressReport) {
-	printSectionSeparator(fmt.Sprintf("INGRESS: %s/%s", ingressReport.Namespace, ingressReport.Name))
+	cfg.printSectionSeparator(fmt.Sprintf("INGRESS: %s/%s", ingressReport.Namespace, ingressReport.Name))
 
 	for _, entries := range ingressReport.Entries {
 		switch entries.Status {
 		case configs.AnnotationConverted:
-			fmt.Printf("  ✅ %s\n", entries.Name)
+			fmt.Fprintf(cfg.writer(), "  ✅ %s\n", entries.Name)
 		case configs.AnnotationWarned:
-			fmt.Printf("  ⚠️  %s\n      → %s\n", entries.Name, entries.Message)
+			fmt.Fprintf(cfg.writer(), "  ⚠️  %s\n      → %s\n", entries.Name, entries.Message)
 		case configs.AnnotationSkipped:
-			fmt.Printf("  ❌ %s\n      → %s\n", entries.Name, entries.Message)
+			fmt.Fprintf(cfg.writer(), "  ❌ %s\n      → %s\n", entries.Name, entries.Message)
 		case configs.AnnotationIgnored:
-			fmt.Printf("  ℹ️  %s\n", entries.Name)
+			fmt.Fprintf(cfg.writer(), "  ℹ️  %s\n", entries.Name)
+		}
+
+		if entries.Source != "" {
+			fmt.Fprintf(cfg.writer(), "      (from %s)\n", entries.Source)
 		}
 	}
 
-	printSubSectionSeparator("SUMMARY")
-	printSummaryText(
+	cfg.printSubSectionSeparator("SUMMARY")
+	cfg.printSummaryText(
 		fmt.Sprintf("Summary for %s/%s", ingressReport.Namespace, ingressReport.Name),
 		summarizeIngress(ingressReport),
 	)
@@ -188,18 +238,34 @@ func (cfg *Config) printIngressReport(ingressReport configs.IngressReport) {
 
 // printGlobalSummary renders the aggregated global summary in plain text format.
 func (cfg *Config) printGlobalSummary(globalReport configs.GlobalReport) {
-	printSectionSeparator("GLOBAL SUMMARY")
-	printSummaryText("Global Summary", summarizeGlobal(globalReport))
+	cfg.printSectionSeparator("GLOBAL SUMMARY")
+	cfg.printSummaryText("Global Summary", summarizeGlobal(globalReport))
+
+	if len(globalReport.Skipped) == 0 {
+		return
+	}
+
+	cfg.printSubSectionSeparator("SKIPPED RESOURCES")
+
+	for _, skipped := range globalReport.Skipped {
+		fmt.Fprintf(cfg.writer(), "  ❌ %s %s\n      → %s\n", skipped.Kind, skippedResourceName(skipped), skipped.Reason)
+
+		if skipped.Source != "" {
+			fmt.Fprintf(cfg.writer(), "      (source: %s)\n", skipped.Source)
+		}
+	}
+
+	fmt.Fprintln(cfg.writer())
 }
 
 // printSummaryText prints a human-readable summary block in plain text.
-func printSummaryText(title string, summaryCounts SummaryCounts) {
-	fmt.Printf("%s\n", color.HiCyanString(title))
-	fmt.Printf("Converted: %s\n", color.HiGreenString(strconv.Itoa(summaryCounts.Converted)))
-	fmt.Printf("Warnings:  %s\n", color.HiYellowString(strconv.Itoa(summaryCounts.Warnings)))
-	fmt.Printf("Skipped:   %s\n", color.HiRedString(strconv.Itoa(summaryCounts.Skipped)))
-	fmt.Printf("Ignored:   %s\n", color.HiBlueString(strconv.Itoa(summaryCounts.Ignored)))
-	fmt.Printf("Result:    %s\n\n", resultLabel(summaryCounts))
+func (cfg *Config) printSummaryText(title string, summaryCounts SummaryCounts) {
+	fmt.Fprintf(cfg.writer(), "%s\n", color.HiCyanString(title))
+	fmt.Fprintf(cfg.writer(), "Converted: %s\n", color.HiGreenString(strconv.Itoa(summaryCounts.Converted)))
+	fmt.Fprintf(cfg.writer(), "Warnings:  %s\n", color.HiYellowString(strconv.Itoa(summaryCounts.Warnings)))
+	fmt.Fprintf(cfg.writer(), "Skipped:   %s\n", color.HiRedString(strconv.Itoa(summaryCounts.Skipped)))
+	fmt.Fprintf(cfg.writer(), "Ignored:   %s\n", color.HiBlueString(strconv.Itoa(summaryCounts.Ignored)))
+	fmt.Fprintf(cfg.writer(), "Result:    %s\n\n", resultLabel(summaryCounts))
 }
 
 // ---------------- Helpers ----------------
@@ -253,6 +319,16 @@ func summarizeGlobal(globalReport configs.GlobalReport) SummaryCounts {
 	return total
 }
 
+// skippedResourceName returns the namespace/name of a skipped resource, or
+// just its name for cluster-scoped objects.
+func skippedResourceName(skipped configs.SkippedResource) string {
+	if skipped.Namespace == "" {
+		return skipped.Name
+	}
+
+	return skipped.Namespace + "/" + skipped.Name
+}
+
 // statusLabelColored returns a colored label for a given annotation status.
 func statusLabelColored(annotationStatus configs.AnnotationStatus) string {
 	switch annotationStatus {
@@ -8,7 +8,6 @@ import (
 
 	"github.com/nikhilsbhat/nginx-traefik-converter/pkg/configs"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 )
 
 const dirPermission = 0o755
@@ -26,6 +25,13 @@ func WriteYAML(res configs.Result, outDir string) error {
 		return err
 	}
 
+	if err := writeObjects(
+		filepath.Join(outDir, "traefikservices.yaml"),
+		toClientObjects(res.TraefikServices),
+	); err != nil {
+		return err
+	}
+
 	if err := writeObjects(
 		filepath.Join(outDir, "ingressroutes.yaml"),
 		toClientObjects(res.IngressRoutes),
@@ -40,6 +46,13 @@ func WriteYAML(res configs.Result, outDir string) error {
 		return err
 	}
 
+	if err := writeObjects(
+		filepath.Join(outDir, "serverstransports.yaml"),
+		toClientObjects(res.ServersTransports),
+	); err != nil {
+		return err
+	}
+
 	if err := writeObjects(
 		filepath.Join(outDir, "certificates.yaml"),
 		toClientObjects(res.Certificates),
@@ -83,19 +96,10 @@ func writeObjects(path string, objs []client.Object) error {
 		}
 	}(file)
 
-	for index, obj := range objs {
-		data, err := yaml.Marshal(obj)
-		if err != nil {
-			return err
-		}
-
-		if index > 0 {
-			if _, err = file.WriteString("\n---\n"); err != nil {
-				return err
-			}
-		}
+	stream := NewStream(file)
 
-		if _, err = file.Write(data); err != nil {
+	for _, obj := range objs {
+		if err = stream.WriteObject(obj); err != nil {
 			return err
 		}
 	}